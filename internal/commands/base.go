@@ -3,16 +3,21 @@ package commands
 import (
 	"fmt"
 	"nativedb/internal/core"
-
-	"golang.org/x/crypto/bcrypt"
+	"strings"
 )
 
 /**
  * @brief 初始化命令处理程序
  */
 func init() {
-	Register("createuser", "Create a new admin user. Usage: createuser <username> <email>", handleCreateUser)
+	Register("createuser", "Create a new user (admin by default). Usage: createuser <username> <email> [role]", handleCreateUser)
+	Register("setrole", "Change user role. Usage: setrole <username> <viewer|contributor|translator|reviewer|admin>", handleSetRole)
 	Register("resetpass", "Reset user password. Usage: resetpass <username>", handleResetPass)
+	Register("listusers", "List all users.", handleListUsers)
+	Register("deleteuser", "Delete a user. Usage: deleteuser <username>", handleDeleteUser)
+	Register("disableuser", "Disable a user. Usage: disableuser <username>", handleDisableUser)
+	Register("enableuser", "Re-enable a disabled user. Usage: enableuser <username>", handleEnableUser)
+	Register("renameuser", "Rename a user. Usage: renameuser <username> <new_username>", handleRenameUser)
 	Register("clearcache", "Clear all Redis cache.", handleClearCache)
 }
 
@@ -23,23 +28,41 @@ func init() {
  */
 func handleCreateUser(args []string) error {
 	if len(args) < 2 {
-		return fmt.Errorf("missing arguments. Usage: createuser <username> <email>")
+		return fmt.Errorf("missing arguments. Usage: createuser <username> <email> [role]")
 	}
 	username := args[0]
 	email := args[1]
-	password := core.GenerateRandomPassword(12)
-
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return err
+	role := core.RoleAdmin
+	if len(args) > 2 {
+		role = strings.ToLower(args[2])
 	}
 
-	_, err = core.DB.Exec("INSERT INTO native_users (username, password_hash, email) VALUES (?, ?, ?)", username, string(hash), email)
+	password, err := core.CreateUser(username, email, role)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %v", err)
 	}
 
-	fmt.Printf("User created successfully!\nUsername: %s\nPassword: %s\n", username, password)
+	fmt.Printf("User created successfully!\nUsername: %s\nRole: %s\nPassword: %s\n", username, role, password)
+	return nil
+}
+
+/**
+ * @brief 修改用户角色
+ * @param args 命令参数
+ * @return error 修改错误
+ */
+func handleSetRole(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("missing arguments. Usage: setrole <username> <role>")
+	}
+	username := args[0]
+	role := strings.ToLower(args[1])
+
+	if err := core.SetUserRole(username, role); err != nil {
+		return fmt.Errorf("failed to update role of '%s': %v", username, err)
+	}
+
+	fmt.Printf("Role updated successfully!\nUsername: %s\nRole: %s\n", username, role)
 	return nil
 }
 
@@ -53,24 +76,100 @@ func handleResetPass(args []string) error {
 		return fmt.Errorf("missing arguments. Usage: resetpass <username>")
 	}
 	username := args[0]
-	password := core.GenerateRandomPassword(12)
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	password, err := core.ResetUserPassword(username)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to reset password of '%s': %v", username, err)
 	}
 
-	res, err := core.DB.Exec("UPDATE native_users SET password_hash = ? WHERE username = ?", string(hash), username)
+	fmt.Printf("Password reset successfully!\nUsername: %s\nNew Password: %s\n", username, password)
+	return nil
+}
+
+/**
+ * @brief 列出所有用户
+ * @param args 命令参数
+ * @return error 查询错误
+ */
+func handleListUsers(args []string) error {
+	users, err := core.ListUsers()
 	if err != nil {
-		return fmt.Errorf("failed to update password: %v", err)
+		return fmt.Errorf("failed to list users: %v", err)
 	}
 
-	rows, _ := res.RowsAffected()
-	if rows == 0 {
-		return fmt.Errorf("user '%s' not found", username)
+	fmt.Printf("%-5s %-20s %-30s %-12s %-8s %s\n", "ID", "USERNAME", "EMAIL", "ROLE", "STATUS", "CREATED")
+	for _, u := range users {
+		status := "active"
+		if u.Disabled {
+			status = "disabled"
+		}
+		fmt.Printf("%-5d %-20s %-30s %-12s %-8s %s\n", u.ID, u.Username, u.Email, u.Role, status, u.CreatedAt.Format("2006-01-02 15:04"))
 	}
+	fmt.Printf("Total: %d\n", len(users))
+	return nil
+}
 
-	fmt.Printf("Password reset successfully!\nUsername: %s\nNew Password: %s\n", username, password)
+/**
+ * @brief 删除用户
+ * @param args 命令参数
+ * @return error 删除错误
+ */
+func handleDeleteUser(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("missing arguments. Usage: deleteuser <username>")
+	}
+	if err := core.DeleteUser(args[0]); err != nil {
+		return fmt.Errorf("failed to delete '%s': %v", args[0], err)
+	}
+	fmt.Printf("User '%s' deleted.\n", args[0])
+	return nil
+}
+
+/**
+ * @brief 禁用用户
+ * @param args 命令参数
+ * @return error 禁用错误
+ */
+func handleDisableUser(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("missing arguments. Usage: disableuser <username>")
+	}
+	if err := core.SetUserDisabled(args[0], true); err != nil {
+		return fmt.Errorf("failed to disable '%s': %v", args[0], err)
+	}
+	fmt.Printf("User '%s' disabled.\n", args[0])
+	return nil
+}
+
+/**
+ * @brief 启用用户
+ * @param args 命令参数
+ * @return error 启用错误
+ */
+func handleEnableUser(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("missing arguments. Usage: enableuser <username>")
+	}
+	if err := core.SetUserDisabled(args[0], false); err != nil {
+		return fmt.Errorf("failed to enable '%s': %v", args[0], err)
+	}
+	fmt.Printf("User '%s' enabled.\n", args[0])
+	return nil
+}
+
+/**
+ * @brief 重命名用户
+ * @param args 命令参数
+ * @return error 重命名错误
+ */
+func handleRenameUser(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("missing arguments. Usage: renameuser <username> <new_username>")
+	}
+	if err := core.RenameUser(args[0], args[1]); err != nil {
+		return fmt.Errorf("failed to rename '%s': %v", args[0], err)
+	}
+	fmt.Printf("User '%s' renamed to '%s'. The user must log in again.\n", args[0], args[1])
 	return nil
 }
 
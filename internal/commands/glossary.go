@@ -0,0 +1,216 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"nativedb/internal/core"
+)
+
+const glossaryUsage = "glossary <list [locale]|add <locale> <term> <translation>|remove <id>|import <file>|export <file> [locale]|check <locale>>"
+
+/**
+ * @brief 初始化术语表命令
+ */
+func init() {
+	Register("glossary", "Manage the translation glossary. Usage: "+glossaryUsage, handleGlossary)
+}
+
+/**
+ * @brief 处理术语表命令
+ * @param args 命令参数
+ * @return error 执行错误
+ */
+func handleGlossary(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("missing subcommand. Usage: %s", glossaryUsage)
+	}
+	if core.DB == nil {
+		if core.Config == nil {
+			return fmt.Errorf("config not loaded")
+		}
+		core.InitDB(core.Config)
+	}
+
+	subCmd := args[0]
+	restArgs := args[1:]
+	switch subCmd {
+	case "list":
+		locale, err := optionalLocale(restArgs, 0)
+		if err != nil {
+			return err
+		}
+		return listGlossary(locale)
+	case "add":
+		if len(restArgs) < 3 {
+			return fmt.Errorf("missing arguments. Usage: glossary add <locale> <term> <translation>")
+		}
+		locale, err := optionalLocale(restArgs, 0)
+		if err != nil {
+			return err
+		}
+		e := core.GlossaryEntry{Locale: locale, Term: restArgs[1], Translation: restArgs[2]}
+		if err := core.SaveGlossaryEntry(&e); err != nil {
+			return err
+		}
+		fmt.Printf("Glossary entry #%d saved: [%s] %s -> %s\n", e.ID, e.Locale, e.Term, e.Translation)
+		return nil
+	case "remove":
+		if len(restArgs) < 1 {
+			return fmt.Errorf("missing arguments. Usage: glossary remove <id>")
+		}
+		id, err := strconv.Atoi(restArgs[0])
+		if err != nil {
+			return fmt.Errorf("invalid id: %s", restArgs[0])
+		}
+		if err := core.DeleteGlossaryEntry(id); err != nil {
+			return fmt.Errorf("failed to remove glossary entry #%d: %v", id, err)
+		}
+		fmt.Printf("Glossary entry #%d removed.\n", id)
+		return nil
+	case "import":
+		if len(restArgs) < 1 {
+			return fmt.Errorf("missing arguments. Usage: glossary import <file>")
+		}
+		return importGlossary(restArgs[0])
+	case "export":
+		if len(restArgs) < 1 {
+			return fmt.Errorf("missing arguments. Usage: glossary export <file> [locale]")
+		}
+		locale, err := optionalLocale(restArgs, 1)
+		if err != nil {
+			return err
+		}
+		return exportGlossary(restArgs[0], locale)
+	case "check":
+		if len(restArgs) < 1 {
+			return fmt.Errorf("missing arguments. Usage: glossary check <locale>")
+		}
+		locale, err := optionalLocale(restArgs, 0)
+		if err != nil {
+			return err
+		}
+		return checkGlossary(locale)
+	default:
+		return fmt.Errorf("unknown subcommand: %s", subCmd)
+	}
+}
+
+/**
+ * @brief 读取并规范化可选的语言参数
+ * @param args 参数列表
+ * @param index 语言参数位置
+ * @return string 语言代码，未提供时为空
+ * @return error 语言代码无效
+ */
+func optionalLocale(args []string, index int) (string, error) {
+	if len(args) <= index {
+		return "", nil
+	}
+	locale, ok := core.NormalizeLocale(args[index])
+	if !ok {
+		return "", fmt.Errorf("invalid language code: %s", args[index])
+	}
+	return locale, nil
+}
+
+/**
+ * @brief 打印术语表
+ * @param locale 语言代码，为空时打印所有语言
+ * @return error 查询错误
+ */
+func listGlossary(locale string) error {
+	entries, err := core.ListGlossary(locale)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%-6s %-8s %-30s %s\n", "ID", "LOCALE", "TERM", "TRANSLATION")
+	for _, e := range entries {
+		fmt.Printf("%-6d %-8s %-30s %s\n", e.ID, e.Locale, e.Term, e.Translation)
+	}
+	fmt.Printf("\nTotal: %d\n", len(entries))
+	return nil
+}
+
+/**
+ * @brief 从 JSON 文件导入术语 (与 export 格式相同)
+ * @param filePath 文件路径
+ * @return error 导入错误
+ */
+func importGlossary(filePath string) error {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	var entries []core.GlossaryEntry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return fmt.Errorf("json parse error: %v", err)
+	}
+
+	imported := 0
+	for _, e := range entries {
+		locale, ok := core.NormalizeLocale(e.Locale)
+		if !ok {
+			fmt.Printf("Skipped '%s': invalid language code '%s'\n", e.Term, e.Locale)
+			continue
+		}
+		e.Locale = locale
+		if err := core.SaveGlossaryEntry(&e); err != nil {
+			fmt.Printf("Skipped '%s': %v\n", e.Term, err)
+			continue
+		}
+		imported++
+	}
+	fmt.Printf("Glossary import finished. Imported: %d/%d\n", imported, len(entries))
+	return nil
+}
+
+/**
+ * @brief 导出术语表到 JSON 文件
+ * @param filePath 文件路径
+ * @param locale 语言代码，为空时导出所有语言
+ * @return error 导出错误
+ */
+func exportGlossary(filePath, locale string) error {
+	entries, err := core.ListGlossary(locale)
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		entries[i].ID = 0
+		entries[i].UpdatedAt = nil
+	}
+	content, err := json.MarshalIndent(entries, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filePath, content, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d glossary entries to %s\n", len(entries), filePath)
+	return nil
+}
+
+/**
+ * @brief 检查已有翻译是否违反术语表
+ * @param locale 语言代码
+ * @return error 查询错误
+ */
+func checkGlossary(locale string) error {
+	violations, err := core.CheckGlossary(locale)
+	if err != nil {
+		return err
+	}
+	if len(violations) == 0 {
+		fmt.Printf("No glossary violations found for '%s'.\n", locale)
+		return nil
+	}
+	fmt.Printf("%-20s %-40s %-24s %s\n", "HASH", "NAME", "TERM", "EXPECTED")
+	for _, v := range violations {
+		fmt.Printf("%-20s %-40s %-24s %s\n", v.NativeHash, v.Name, v.Term, v.Expected)
+	}
+	fmt.Printf("\n%d violations found.\n", len(violations))
+	return nil
+}
@@ -7,17 +7,15 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"nativedb/internal/core"
 )
 
-const (
-	URL_NATIVE_GTA     = "https://static.cfx.re/natives/natives.json"
-	URL_NATIVE_CFX     = "https://static.cfx.re/natives/natives_cfx.json"
-	URL_NATIVE_GITHUB  = "https://github.com/alloc8or/gta5-nativedb-data/raw/master/natives.json"
-	FILE_NATIVE_GITHUB = "natives_github.json"
-)
+// importReportDir 为导入变更报告的输出目录
+const importReportDir = "import_reports"
 
 type NativeDoc struct {
 	Name        string          `json:"name"`
@@ -49,7 +47,7 @@ type NativeExample struct {
  * @brief 初始化导入命令
  */
 func init() {
-	Register("import", "Import data. Usage: import <native|nativecfx|sources> [file/path]", handleImport)
+	Register("import", "Import data. Usage: import <native|nativecfx|all|list|<source>|sources|manifest> [file/path] [--dry-run] [--offline] [--game <game>]", handleImport)
 }
 
 /**
@@ -69,13 +67,12 @@ func CheckAndAutoImport() {
 
 	if count == 0 {
 		log.Println("[AutoImport] Database is empty. Starting automatic import...")
-		log.Println("[AutoImport] Processing natives.json (GTA5)...")
-		if err := runImportNative("natives.json", URL_NATIVE_GTA, "gta5", true); err != nil {
-			log.Printf("[AutoImport] Failed to import natives.json: %v", err)
-		}
-		log.Println("[AutoImport] Processing natives_cfx.json (CFX)...")
-		if err := runImportNative("natives_cfx.json", URL_NATIVE_CFX, "gta5", true); err != nil {
-			log.Printf("[AutoImport] Failed to import natives_cfx.json: %v", err)
+		opts := importOptions{Offline: core.Config.DataOffline}
+		for _, src := range core.Config.SourcesOf(core.SourcePrimary, "") {
+			log.Printf("[AutoImport] Processing %s (%s)...", src.Path, src.Name)
+			if _, err := runImportNative(src, src.Path, opts); err != nil {
+				log.Printf("[AutoImport] Failed to import %s: %v", src.Path, err)
+			}
 		}
 		log.Println("[AutoImport] Automatic import completed.")
 	}
@@ -88,11 +85,14 @@ func CheckAndAutoImport() {
  */
 func handleImport(args []string) error {
 	if len(args) < 1 {
-		return fmt.Errorf("missing subcommand. usage: import <native|nativecfx|sources> [args]")
+		return fmt.Errorf("missing subcommand. usage: import <native|nativecfx|all|list|<source>|sources|manifest> [args]")
 	}
 
 	subCmd := args[0]
-	restArgs := args[1:]
+	restArgs, opts, err := parseImportArgs(args[1:])
+	if err != nil {
+		return err
+	}
 
 	if core.DB == nil {
 		if core.Config == nil {
@@ -102,78 +102,289 @@ func handleImport(args []string) error {
 	}
 
 	switch subCmd {
-	case "native":
-		targetFile := "natives.json"
-		if len(restArgs) > 0 {
-			targetFile = restArgs[0]
-		}
-		return runImportNative(targetFile, URL_NATIVE_GTA, "gta5", true)
-	case "nativecfx":
-		targetFile := "natives_cfx.json"
-		if len(restArgs) > 0 {
-			targetFile = restArgs[0]
+	case "native", "nativecfx":
+		// 兼容旧的子命令，分别对应默认的 gta5 与 cfx 数据源
+		return importSourceByName(map[string]string{"native": "gta5", "nativecfx": "cfx"}[subCmd], restArgs, opts)
+	case "all":
+		for _, src := range core.Config.SourcesOf(core.SourcePrimary, opts.Game) {
+			if _, err := runImportNative(src, src.Path, opts); err != nil {
+				return fmt.Errorf("%s: %v", src.Name, err)
+			}
 		}
-		return runImportNative(targetFile, URL_NATIVE_CFX, "gta5", true)
+		return nil
+	case "list":
+		listDataSources()
+		return nil
 	case "sources":
 		targetDir := "natives"
 		if len(restArgs) > 0 {
 			targetDir = restArgs[0]
 		}
-		return runImportSources(targetDir)
+		game := opts.Game
+		if game == "" {
+			game = core.DefaultGame
+		}
+		return runImportSources(targetDir, game, opts.DryRun)
+	case "manifest":
+		return pinDataManifest(restArgs)
 	case "clear":
 		return clearNatives()
 	default:
-		return fmt.Errorf("unknown subcommand: %s", subCmd)
+		if _, ok := core.Config.FindDataSource(subCmd); ok {
+			return importSourceByName(subCmd, restArgs, opts)
+		}
+		return fmt.Errorf("unknown subcommand or data source: %s", subCmd)
 	}
 }
 
 /**
- * @brief 运行导入原生函数数据
- * @param filePath 文件路径
- * @param downloadURL 下载 URL
- * @param defaultGame 默认游戏
- * @param usePatch 是否使用补丁
+ * @brief 导入指定名称的主数据源
+ * @param name 数据源名称
+ * @param args 其余参数，第一个参数可指定代替数据源路径的本地文件
+ * @param opts 导入开关
  * @return error 导入错误
  */
-func runImportNative(filePath, downloadURL, defaultGame string, usePatch bool) error {
-	if !core.FileExists(filePath) {
-		fmt.Printf("File '%s' not found. Downloading from %s...\n", filePath, downloadURL)
-		if err := core.DownloadFile(filePath, downloadURL); err != nil {
-			return fmt.Errorf("download failed: %v", err)
+func importSourceByName(name string, args []string, opts importOptions) error {
+	src, ok := core.Config.FindDataSource(name)
+	if !ok {
+		return fmt.Errorf("data source %s is not configured", name)
+	}
+	if src.Type != core.SourcePrimary {
+		return fmt.Errorf("data source %s is a patch and is applied when importing %s sources", name, src.Game)
+	}
+	if opts.Game != "" && src.Game != opts.Game {
+		return fmt.Errorf("data source %s belongs to %s, not %s", name, src.Game, opts.Game)
+	}
+	targetFile := src.Path
+	if len(args) > 0 {
+		targetFile = args[0]
+	}
+	_, err := runImportNative(src, targetFile, opts)
+	return err
+}
+
+/**
+ * @brief 输出配置的数据源
+ */
+func listDataSources() {
+	fmt.Printf("%-12s %-8s %-6s %-8s %-8s %s\n", "NAME", "TYPE", "GAME", "APISET", "PRIORITY", "PATH / URL")
+	for _, src := range core.Config.DataSources {
+		location := src.Path
+		if src.URL != "" {
+			location += " <- " + src.URL
+		}
+		fmt.Printf("%-12s %-8s %-6s %-8s %-8d %s\n", src.Name, src.Type, src.Game, src.Apiset, src.Priority, location)
+	}
+}
+
+// importOptions 为导入命令的开关
+type importOptions struct {
+	// DryRun 只计算变更，不下载也不写入
+	DryRun bool
+	// Offline 从不访问网络，缺少的数据文件直接报错
+	Offline bool
+	// Fetched 表示数据文件已由调用方刷新，导入时不再访问网络
+	Fetched bool
+	// Game 只导入该游戏的数据源，为空时不限
+	Game string
+}
+
+/**
+ * @brief 从参数中分离 --dry-run、--offline 与 --game 开关，开关可以出现在任意位置
+ * @param args 命令参数
+ * @return []string 其余参数
+ * @return importOptions 导入开关 (离线模式同时受 data_offline 配置控制)
+ * @return error 游戏无效
+ */
+func parseImportArgs(args []string) ([]string, importOptions, error) {
+	rest := make([]string, 0, len(args))
+	opts := importOptions{Offline: core.Config.DataOffline}
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--dry-run" || arg == "-dry-run":
+			opts.DryRun = true
+		case arg == "--offline" || arg == "-offline":
+			opts.Offline = true
+		case arg == "--game" || arg == "-game" || strings.HasPrefix(arg, "--game="):
+			value := strings.TrimPrefix(arg, "--game=")
+			if value == arg {
+				if i+1 >= len(args) {
+					return nil, opts, fmt.Errorf("--game requires a value")
+				}
+				i++
+				value = args[i]
+			}
+			game, ok := core.NormalizeGame(value)
+			if !ok {
+				return nil, opts, fmt.Errorf("unknown game: %s", value)
+			}
+			opts.Game = game
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return rest, opts, nil
+}
+
+/**
+ * @brief 确保数据文件可用：缺少时下载，之前下载过的文件通过条件请求刷新，没有下载记录的文件 (如旧版本下载的文件) 完整下载一次，离线模式下不访问网络
+ * @param path 文件路径
+ * @param url 下载 URL，为空时只使用本地文件
+ * @param offline 是否离线
+ * @param manifest 校验清单，下载的内容与清单不一致时不会替换原文件
+ * @return bool 文件是否有更新
+ * @return error 文件不可用
+ */
+func fetchDataFile(path, url string, offline bool, manifest core.DataManifest) (bool, error) {
+	exists := core.FileExists(path)
+	switch {
+	case offline && !exists:
+		return false, fmt.Errorf("file '%s' not found and offline mode is enabled", path)
+	case url == "" && !exists:
+		return false, fmt.Errorf("file '%s' not found and the data source has no url", path)
+	case offline || url == "":
+		return false, nil
+	case !exists:
+		fmt.Printf("File '%s' not found. Downloading from %s...\n", path, url)
+		if _, err := core.DownloadFile(path, url, manifest.Expected(path)); err != nil {
+			return false, fmt.Errorf("download failed: %v", err)
 		}
 		fmt.Println("Download complete.")
+		return true, nil
+	default:
+		if !core.IsDownloaded(path) {
+			fmt.Printf("'%s' has no download record, fetching it once from %s...\n", path, url)
+		}
+		// 本地文件仍然可用，刷新失败只提示
+		updated, err := core.DownloadFile(path, url, manifest.Expected(path))
+		if err != nil {
+			fmt.Printf("Could not refresh '%s', using the local copy: %v\n", path, err)
+			return false, nil
+		}
+		if updated {
+			fmt.Printf("'%s' updated from %s.\n", path, url)
+		} else {
+			fmt.Printf("'%s' is up to date.\n", path)
+		}
+		return updated, nil
 	}
+}
 
-	patchMap := make(map[string]NativeDoc)
-	if usePatch {
-		if !core.FileExists(FILE_NATIVE_GITHUB) {
-			if err := core.DownloadFile(FILE_NATIVE_GITHUB, URL_NATIVE_GITHUB); err == nil {
-				fmt.Println("Patch file downloaded.")
+/**
+ * @brief 将数据文件当前的 SHA-256 写入校验清单
+ * @param files 文件列表，为空时固定所有数据源中已存在的文件
+ * @return error 读取或写入错误
+ */
+func pinDataManifest(files []string) error {
+	if len(files) == 0 {
+		for _, src := range core.Config.DataSources {
+			if core.FileExists(src.Path) {
+				files = append(files, src.Path)
 			}
 		}
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no data files to pin")
+	}
+
+	manifest, err := core.LoadDataManifest(core.Config.DataManifest)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		sum, err := core.FileSHA256(f)
+		if err != nil {
+			return err
+		}
+		manifest[filepath.Base(f)] = sum
+		fmt.Printf("%s  %s\n", sum, filepath.Base(f))
+	}
+	if err := core.SaveDataManifest(core.Config.DataManifest, manifest); err != nil {
+		return err
+	}
+	fmt.Printf("Pinned %d files in %s.\n", len(files), core.Config.DataManifest)
+	return nil
+}
+
+/**
+ * @brief 运行导入原生函数数据，同一游戏的补丁数据源按优先级合并
+ * @param src 主数据源，提供下载 URL 与默认的游戏和 apiset
+ * @param filePath 文件路径
+ * @param opts 导入开关，演练模式只计算变更而不下载、不写入数据库与文件
+ * @return *core.ImportReport 变更报告
+ * @return error 导入错误
+ */
+func runImportNative(src core.DataSource, filePath string, opts importOptions) (*core.ImportReport, error) {
+	dryRun := opts.DryRun
+	offline := opts.Offline || opts.Fetched || dryRun
+	if dryRun {
+		fmt.Println("Dry run: nothing will be downloaded or written.")
+	} else if opts.Offline {
+		fmt.Println("Offline mode: using local data files only.")
+	}
+
+	manifest, err := core.LoadDataManifest(core.Config.DataManifest)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fetchDataFile(filePath, src.URL, offline, manifest); err != nil {
+		return nil, err
+	}
+	if err := manifest.Verify(filePath); err != nil {
+		return nil, err
+	}
 
-		if core.FileExists(FILE_NATIVE_GITHUB) {
-			if pm, err := loadNativeMap(FILE_NATIVE_GITHUB); err == nil {
-				patchMap = pm
-				fmt.Printf("Patch data loaded (%d entries).\n", len(patchMap))
+	// 补丁按优先级升序加载，同一函数以优先级高的补丁为准
+	patchMap := make(map[string]NativeDoc)
+	for _, patch := range core.Config.SourcesOf(core.SourcePatch, src.Game) {
+		if _, err := fetchDataFile(patch.Path, patch.URL, offline, manifest); err != nil {
+			fmt.Printf("Patch data %s unavailable, skipping: %v\n", patch.Name, err)
+		} else if err := manifest.Verify(patch.Path); err != nil {
+			return nil, err
+		} else if pm, err := loadNativeMap(patch.Path); err == nil {
+			for hash, doc := range pm {
+				patchMap[hash] = doc
 			}
+			fmt.Printf("Patch data %s loaded (%d entries).\n", patch.Name, len(pm))
 		}
 	}
 
 	fmt.Printf("Reading %s...\n", filePath)
 	fileContent, err := os.ReadFile(filePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var data map[string]map[string]NativeDoc
 	if err := json.Unmarshal(fileContent, &data); err != nil {
-		return fmt.Errorf("json parse error: %v", err)
+		return nil, fmt.Errorf("json parse error: %v", err)
 	}
 
-	countProcessed := 0
-	countUpdated := 0
+	existing, err := loadNativeRows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load existing natives: %v", err)
+	}
+	gamesByHash := hashGames(existing)
+	exampleKeys, err := loadExampleKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load existing examples: %v", err)
+	}
+
+	// 整个文件在一个事务中写入，任一写入失败都会回滚，演练模式不开启事务
+	var w *nativeWriter
+	if !dryRun {
+		if w, err = newNativeWriter(); err != nil {
+			return nil, fmt.Errorf("failed to start import transaction: %v", err)
+		}
+		defer w.rollback()
+	}
+
+	report := core.NewImportReport(filepath.Base(filePath), src.Game)
+	seen := make(map[string]bool)
+	namespaces := make(map[string]bool)
 	countExamples := 0
+	countUpdated := 0
+	countOutdated := int64(0)
 
 	for namespace, natives := range data {
 		for hash, doc := range natives {
@@ -191,72 +402,410 @@ func runImportNative(filePath, downloadURL, defaultGame string, usePatch bool) e
 			}
 
 			if doc.Apiset == "" {
-				doc.Apiset = "client"
+				doc.Apiset = src.Apiset
 			}
+			doc.Game = strings.ToLower(doc.Game)
 			if doc.Game == "" {
-				doc.Game = defaultGame
+				doc.Game = src.Game
 			}
+			namespaces[doc.Game+"/"+namespace] = true
 
 			buildNum := parseBuildNumber(doc.Build)
 
-			finalParamsJSON, err := mergeParams(hash, doc.Params)
+			// 函数以 (游戏, 哈希) 为标识，其他游戏的同一哈希是另一个函数
+			key := nativeKey(doc.Game, hash)
+			seen[key] = true
+			old, exists := existing[key]
+			if others := otherGames(gamesByHash[hash], doc.Game); len(others) > 0 {
+				report.Conflicts = append(report.Conflicts, core.NativeChange{Hash: hash, Namespace: namespace, Name: doc.Name, Game: strings.Join(others, ",")})
+			}
+			finalParamsJSON, err := mergeParams(old.Params, doc.Params)
 			if err != nil {
 				log.Printf("Error merging params for %s: %v", hash, err)
 				continue
 			}
 
-			var exists int
-			core.DB.QueryRow("SELECT COUNT(*) FROM natives WHERE hash = ?", hash).Scan(&exists)
-
-			if exists == 0 {
-				_, err := core.DB.Exec(`
-					INSERT INTO natives (hash, jhash, name, name_sp, namespace, params, return_type, description_original, apiset, game, build_number)
-					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-				`, hash, doc.JHash, doc.Name, doc.NameSP, namespace, finalParamsJSON, doc.Results, doc.Description, doc.Apiset, doc.Game, buildNum)
-				if err != nil {
-					log.Printf("Insert error %s: %v", hash, err)
+			change := core.NativeChange{Hash: hash, Namespace: namespace, Name: doc.Name, Game: doc.Game}
+			row := nativeRow{Hash: hash, JHash: doc.JHash, Name: doc.Name, NameSP: doc.NameSP, Namespace: namespace, Params: string(finalParamsJSON),
+				ReturnType: doc.Results, Description: doc.Description, Apiset: doc.Apiset, Game: doc.Game, Build: buildNum}
+			if !exists {
+				if w != nil {
+					if _, err := w.insertNative.Exec(row.Game, hash, row.JHash, row.Name, row.NameSP, row.Namespace, row.Params, row.ReturnType, row.Description, row.Apiset, row.Build); err != nil {
+						return nil, fmt.Errorf("insert %s failed, import rolled back: %v", hash, err)
+					}
 				}
-				countUpdated++
+				report.Added = append(report.Added, change)
+				report.Written = append(report.Written, hash)
+				gamesByHash[hash] = append(gamesByHash[hash], doc.Game)
 			} else {
-				var updateSQL string
-				if core.Config.DbType == "sqlite" {
-					updateSQL = `UPDATE natives SET jhash=?, name=?, name_sp=?, namespace=?, params=?, return_type=?, description_original=?, apiset=?, game=?, build_number=?, updated_at=CURRENT_TIMESTAMP WHERE hash=?`
+				diffNative(report, change, old, doc)
+
+				// 内容未变化的函数不再写入，也不会影响已有翻译
+				if sameNative(row, old) {
+					report.Unchanged++
 				} else {
-					updateSQL = `UPDATE natives SET jhash=?, name=?, name_sp=?, namespace=?, params=?, return_type=?, description_original=?, apiset=?, game=?, build_number=?, updated_at=NOW() WHERE hash=?`
+					countUpdated++
+					report.Written = append(report.Written, hash)
+					if w != nil {
+						if _, err := w.updateNative.Exec(row.JHash, row.Name, row.NameSP, row.Namespace, row.Params, row.ReturnType, row.Description, row.Apiset, row.Build, row.Game, hash); err != nil {
+							return nil, fmt.Errorf("update %s failed, import rolled back: %v", hash, err)
+						}
+						n, err := core.MarkOutdatedTranslations(w.tx, row.Game, hash, core.SourceFingerprint(row.Description, finalParamsJSON))
+						if err != nil {
+							return nil, fmt.Errorf("failed to mark outdated translations of %s, import rolled back: %v", hash, err)
+						}
+						countOutdated += n
+					}
 				}
+			}
 
-				_, err := core.DB.Exec(updateSQL, doc.JHash, doc.Name, doc.NameSP, namespace, finalParamsJSON, doc.Results, doc.Description, doc.Apiset, doc.Game, buildNum, hash)
+			if len(doc.Examples) > 0 {
+				added, err := importExamples(w, doc.Game, hash, doc.Examples, exampleKeys)
 				if err != nil {
-					log.Printf("Update error %s: %v", hash, err)
+					return nil, fmt.Errorf("failed to add examples of %s, import rolled back: %v", hash, err)
 				}
-				countUpdated++
+				countExamples += added
 			}
 
-			if len(doc.Examples) > 0 {
-				countExamples += importExamples(hash, doc.Examples)
+			report.Processed++
+			if report.Processed%1000 == 0 {
+				fmt.Printf("Processed %d natives...\r", report.Processed)
 			}
+		}
+	}
 
-			countProcessed++
-			if countProcessed%1000 == 0 {
-				fmt.Printf("Processed %d natives...\r", countProcessed)
-			}
+	// 只有文件中出现的 游戏/命名空间 才参与删除检测，避免 natives_cfx.json 把游戏函数全部报告为已删除，
+	// 也避免 RDR3 数据把 GTA5 中同名命名空间的函数报告为已删除
+	for key, old := range existing {
+		if !seen[key] && namespaces[old.Game+"/"+old.Namespace] {
+			report.Removed = append(report.Removed, core.NativeChange{Hash: old.Hash, Namespace: old.Namespace, Name: old.Name, Game: old.Game})
 		}
 	}
+	report.FinishedAt = time.Now()
 
-	fmt.Printf("\nImport finished. Processed: %d, Examples added: %d\n", countProcessed, countExamples)
-	return nil
+	if dryRun {
+		report.Sort()
+		fmt.Printf("\nDry run finished. Processed: %d, Would insert: %d, Would update: %d, Examples to add: %d\n", report.Processed, len(report.Added), countUpdated, countExamples)
+		fmt.Println(report.Summary())
+		return report, nil
+	}
+
+	if err := w.commit(); err != nil {
+		return nil, fmt.Errorf("commit failed, import rolled back: %v", err)
+	}
+	fmt.Printf("\nImport finished. Processed: %d, Inserted: %d, Updated: %d, Examples added: %d\n", report.Processed, len(report.Added), countUpdated, countExamples)
+	if countOutdated > 0 {
+		fmt.Printf("Source text changed for %d translations, marked as outdated. Run 'translate --list-outdated' to review.\n", countOutdated)
+	}
+	writeImportReport(report)
+
+	fmt.Println("Rebuilding full-text index...")
+	if err := core.RebuildSearchIndex(); err != nil {
+		log.Printf("Failed to rebuild full-text index: %v", err)
+	}
+	return report, nil
+}
+
+// nativeRow 为导入前数据库中已有函数的快照
+type nativeRow struct {
+	Hash        string
+	JHash       string
+	Name        string
+	NameSP      string
+	Namespace   string
+	Params      string
+	ReturnType  string
+	Description string
+	Apiset      string
+	Game        string
+	Build       int
+}
+
+/**
+ * @brief 判断导入的函数与已有函数是否一致，参数按解码后的内容比较 (翻译写回的参数 JSON 与导入生成的字段格式不同)
+ * @param a 导入的函数
+ * @param b 已有函数
+ * @return bool 是否一致
+ */
+func sameNative(a, b nativeRow) bool {
+	paramsA, paramsB := a.Params, b.Params
+	a.Params, b.Params = "", ""
+	return a == b && sameParams(paramsA, paramsB)
+}
+
+/**
+ * @brief 比较两个参数 JSON 的内容
+ * @param a 参数 JSON
+ * @param b 参数 JSON
+ * @return bool 是否一致
+ */
+func sameParams(a, b string) bool {
+	if a == b {
+		return true
+	}
+	var paramsA, paramsB []NativeParam
+	if a != "" && json.Unmarshal([]byte(a), &paramsA) != nil {
+		return false
+	}
+	if b != "" && json.Unmarshal([]byte(b), &paramsB) != nil {
+		return false
+	}
+	if len(paramsA) != len(paramsB) {
+		return false
+	}
+	for i := range paramsA {
+		if paramsA[i] != paramsB[i] {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+ * @brief 生成函数在导入快照中的键
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @return string 键
+ */
+func nativeKey(game, hash string) string {
+	return game + "/" + hash
+}
+
+/**
+ * @brief 读取数据库中已有函数的快照
+ * @return map[string]nativeRow 以 nativeKey 为键的函数映射
+ * @return error 查询错误
+ */
+func loadNativeRows() (map[string]nativeRow, error) {
+	rows, err := core.DB.Query(`SELECT hash, COALESCE(jhash, ''), COALESCE(name, ''), COALESCE(name_sp, ''), namespace, COALESCE(params, ''),
+		COALESCE(return_type, ''), COALESCE(description_original, ''), COALESCE(apiset, ''), game, COALESCE(build_number, 0) FROM natives`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	m := make(map[string]nativeRow)
+	for rows.Next() {
+		var r nativeRow
+		if err := rows.Scan(&r.Hash, &r.JHash, &r.Name, &r.NameSP, &r.Namespace, &r.Params, &r.ReturnType, &r.Description, &r.Apiset, &r.Game, &r.Build); err != nil {
+			return nil, err
+		}
+		m[nativeKey(r.Game, r.Hash)] = r
+	}
+	return m, rows.Err()
+}
+
+/**
+ * @brief 按哈希汇总已有函数所属的游戏，用于报告跨游戏的哈希冲突
+ * @param existing 已有函数的快照
+ * @return map[string][]string 哈希到游戏列表的映射
+ */
+func hashGames(existing map[string]nativeRow) map[string][]string {
+	m := make(map[string][]string, len(existing))
+	for _, r := range existing {
+		m[r.Hash] = append(m[r.Hash], r.Game)
+	}
+	return m
+}
+
+/**
+ * @brief 获取除指定游戏外的其他游戏
+ * @param games 游戏列表
+ * @param game 排除的游戏
+ * @return []string 其他游戏 (按代码排序)
+ */
+func otherGames(games []string, game string) []string {
+	var others []string
+	for _, g := range games {
+		if g != game {
+			others = append(others, g)
+		}
+	}
+	sort.Strings(others)
+	return others
+}
+
+// nativeWriter 在单个事务中使用预编译语句写入一个文件的函数数据
+type nativeWriter struct {
+	tx            *sql.Tx
+	insertNative  *sql.Stmt
+	updateNative  *sql.Stmt
+	insertExample *sql.Stmt
+}
+
+/**
+ * @brief 开启导入事务并准备写入语句
+ * @return *nativeWriter 写入器
+ * @return error 数据库错误
+ */
+func newNativeWriter() (*nativeWriter, error) {
+	tx, err := core.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	updateSQL := `UPDATE natives SET jhash=?, name=?, name_sp=?, namespace=?, params=?, return_type=?, description_original=?, apiset=?, build_number=?, updated_at=CURRENT_TIMESTAMP WHERE game=? AND hash=?`
+	if core.Config.DbType != "sqlite" {
+		updateSQL = strings.Replace(updateSQL, "CURRENT_TIMESTAMP", "NOW()", 1)
+	}
+
+	w := &nativeWriter{tx: tx}
+	w.insertNative, err = tx.Prepare(`INSERT INTO natives (game, hash, jhash, name, name_sp, namespace, params, return_type, description_original, apiset, build_number)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err == nil {
+		w.updateNative, err = tx.Prepare(updateSQL)
+	}
+	if err == nil {
+		w.insertExample, err = tx.Prepare("INSERT INTO native_examples (game, native_hash, language, code, contributor) VALUES (?, ?, ?, ?, 'System_Import')")
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return w, nil
+}
+
+/**
+ * @brief 提交导入事务 (预编译语句随事务关闭)
+ * @return error 提交错误
+ */
+func (w *nativeWriter) commit() error {
+	return w.tx.Commit()
+}
+
+/**
+ * @brief 回滚导入事务，已提交时无效
+ */
+func (w *nativeWriter) rollback() {
+	w.tx.Rollback()
+}
+
+/**
+ * @brief 生成示例代码的去重键
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param lang 语言
+ * @param code 代码
+ * @return string 去重键
+ */
+func exampleKey(game, hash, lang, code string) string {
+	return game + "\x00" + hash + "\x00" + lang + "\x00" + code
+}
+
+/**
+ * @brief 读取已有示例代码的去重键
+ * @return map[string]bool 去重键集合
+ * @return error 查询错误
+ */
+func loadExampleKeys() (map[string]bool, error) {
+	rows, err := core.DB.Query("SELECT game, native_hash, language, code FROM native_examples")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make(map[string]bool)
+	for rows.Next() {
+		var game, hash, lang, code string
+		if err := rows.Scan(&game, &hash, &lang, &code); err != nil {
+			return nil, err
+		}
+		keys[exampleKey(game, hash, lang, code)] = true
+	}
+	return keys, rows.Err()
+}
+
+/**
+ * @brief 比较已有函数与导入数据，将改名、签名变化与描述变化记录到报告
+ * @param report 导入报告
+ * @param change 函数的基本信息
+ * @param old 已有函数
+ * @param doc 导入数据
+ */
+func diffNative(report *core.ImportReport, change core.NativeChange, old nativeRow, doc NativeDoc) {
+	if old.Name != doc.Name {
+		renamed := change
+		renamed.OldName = old.Name
+		report.Renamed = append(report.Renamed, renamed)
+	}
+
+	var oldParams []NativeParam
+	if old.Params != "" {
+		_ = json.Unmarshal([]byte(old.Params), &oldParams)
+	}
+	oldSignature := formatSignature(old.ReturnType, old.Name, oldParams)
+	newSignature := formatSignature(doc.Results, doc.Name, doc.Params)
+	// 仅改名不算签名变化
+	if formatSignature(old.ReturnType, doc.Name, oldParams) != newSignature {
+		changed := change
+		changed.OldSignature, changed.NewSignature = oldSignature, newSignature
+		report.SignatureChanged = append(report.SignatureChanged, changed)
+	}
+
+	if strings.TrimSpace(old.Description) != strings.TrimSpace(doc.Description) {
+		report.DescriptionChanged = append(report.DescriptionChanged, change)
+	}
 }
 
 /**
- * @brief 清除导入的原生函数数据
+ * @brief 生成函数签名 (如 void SET_ENTITY_COORDS(Entity entity, float x))
+ * @param returnType 返回类型
+ * @param name 函数名
+ * @param params 参数列表
+ * @return string 函数签名
+ */
+func formatSignature(returnType, name string, params []NativeParam) string {
+	if returnType == "" {
+		returnType = "void"
+	}
+	parts := make([]string, 0, len(params))
+	for _, p := range params {
+		parts = append(parts, strings.TrimSpace(p.Type+" "+p.Name))
+	}
+	return fmt.Sprintf("%s %s(%s)", returnType, name, strings.Join(parts, ", "))
+}
+
+/**
+ * @brief 保存导入记录，输出变更摘要并将完整报告写入 import_reports 目录
+ * @param report 导入报告
+ */
+func writeImportReport(report *core.ImportReport) {
+	report.Sort()
+	fmt.Println(report.Summary())
+
+	name := fmt.Sprintf("import-%s.json", report.StartedAt.Format("20060102-150405"))
+	if err := core.SaveImportRun(report); err != nil {
+		log.Printf("Failed to save import run: %v", err)
+	} else {
+		name = fmt.Sprintf("import-%d.json", report.ID)
+	}
+
+	if err := os.MkdirAll(importReportDir, 0755); err != nil {
+		log.Printf("Failed to create %s: %v", importReportDir, err)
+		return
+	}
+	content, _ := json.MarshalIndent(report, "", "  ")
+	path := filepath.Join(importReportDir, name)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		log.Printf("Failed to write import report: %v", err)
+		return
+	}
+	fmt.Printf("Change report written to %s\n", path)
+}
+
+/**
+ * @brief 清除可重新下载的数据源文件，只有本地路径的数据源不会被删除
  * @return error 清除错误
  */
 func clearNatives() error {
-	filesToDelete := []string{FILE_NATIVE_GITHUB, "natives.json", "natives_cfx.json"}
-	for _, file := range filesToDelete {
-		if err := os.Remove(file); err != nil {
-			return fmt.Errorf("failed to delete %s: %v", file, err)
+	for _, src := range core.Config.DataSources {
+		if src.URL == "" {
+			continue
+		}
+		if err := os.Remove(src.Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete %s: %v", src.Path, err)
 		}
+		os.Remove(src.Path + ".meta")
 	}
 	return nil
 }
@@ -313,29 +862,20 @@ func loadNativeMap(filePath string) (map[string]NativeDoc, error) {
 }
 
 /**
- * @brief 合并参数
- * @param hash 哈希值
+ * @brief 合并参数，保留已有参数的中文描述
+ * @param oldParamsJSON 数据库中已有的参数 JSON，新函数为空
  * @param newParams 新参数
  * @return []byte 合并后的参数 JSON
  * @return error 合并错误
  */
-func mergeParams(hash string, newParams []NativeParam) ([]byte, error) {
+func mergeParams(oldParamsJSON string, newParams []NativeParam) ([]byte, error) {
 	if len(newParams) == 0 {
 		return []byte("[]"), nil
 	}
 
-	var oldParamsJSON []byte
-	err := core.DB.QueryRow("SELECT params FROM natives WHERE hash = ?", hash).Scan(&oldParamsJSON)
-	if err == sql.ErrNoRows {
-		return json.Marshal(newParams)
-	}
-	if err != nil {
-		return nil, err
-	}
-
 	var oldParams []NativeParam
-	if len(oldParamsJSON) > 0 {
-		_ = json.Unmarshal(oldParamsJSON, &oldParams)
+	if oldParamsJSON != "" {
+		_ = json.Unmarshal([]byte(oldParamsJSON), &oldParams)
 	}
 
 	cnMap := make(map[string]string)
@@ -355,44 +895,53 @@ func mergeParams(hash string, newParams []NativeParam) ([]byte, error) {
 }
 
 /**
- * @brief 导入示例代码
+ * @brief 导入示例代码，跳过已存在的示例
+ * @param w 导入事务，演练模式为 nil，只统计将要新增的示例
+ * @param game 游戏
  * @param hash 哈希值
  * @param examples 示例代码
+ * @param keys 已存在示例的键，新增的示例会加入其中
  * @return int 导入的示例数量
+ * @return error 写入错误
  */
-func importExamples(hash string, examples []NativeExample) int {
+func importExamples(w *nativeWriter, game, hash string, examples []NativeExample, keys map[string]bool) (int, error) {
 	added := 0
 	for _, ex := range examples {
 		lang := strings.ToLower(ex.Lang)
 		code := strings.TrimSpace(ex.Code)
-		if code == "" {
+		key := exampleKey(game, hash, lang, code)
+		if code == "" || keys[key] {
 			continue
 		}
 
-		var exists int
-		err := core.DB.QueryRow("SELECT 1 FROM native_examples WHERE native_hash = ? AND language = ? AND code = ?", hash, lang, code).Scan(&exists)
-		if err == sql.ErrNoRows {
-			_, err := core.DB.Exec("INSERT INTO native_examples (native_hash, language, code, contributor) VALUES (?, ?, ?, 'System_Import')", hash, lang, code)
-			if err == nil {
-				added++
+		if w != nil {
+			if _, err := w.insertExample.Exec(game, hash, lang, code); err != nil {
+				return added, err
 			}
 		}
+		keys[key] = true
+		added++
 	}
-	return added
+	return added, nil
 }
 
 /**
  * @brief 运行导入原生函数源数据
  * @param dirPath 目录路径
+ * @param game 源码所属的游戏，文件只与该游戏的函数匹配
+ * @param dryRun 演练模式，只统计将要新增与更新的源码而不写入数据库
  * @return error 导入错误
  */
-func runImportSources(dirPath string) error {
+func runImportSources(dirPath, game string, dryRun bool) error {
 	if !core.FileExists(dirPath) && !isDir(dirPath) {
 		return fmt.Errorf("directory '%s' not found", dirPath)
 	}
+	if dryRun {
+		fmt.Println("Dry run: nothing will be written.")
+	}
 
-	fmt.Println("Building hash map from database...")
-	hashMap, err := buildHashMap()
+	fmt.Printf("Building hash map of %s natives from database...\n", game)
+	hashMap, err := buildHashMap(game)
 	if err != nil {
 		return err
 	}
@@ -402,7 +951,10 @@ func runImportSources(dirPath string) error {
 		return err
 	}
 
-	count := 0
+	inserted := 0
+	updated := 0
+	unchanged := 0
+	unmatched := 0
 	skipped := 0
 
 	fmt.Println("Scanning files...")
@@ -416,45 +968,63 @@ func runImportSources(dirPath string) error {
 
 		targetHash := matchHash(nameNoExt, hashMap)
 		if targetHash == "" {
+			unmatched++
 			continue
 		}
 
 		contentBytes, err := os.ReadFile(filepath.Join(dirPath, fileName))
 		if err != nil {
 			log.Printf("Failed to read %s: %v", fileName, err)
+			skipped++
 			continue
 		}
 		content := string(contentBytes)
 
 		var id int
-		err = core.DB.QueryRow("SELECT id FROM native_sources WHERE native_hash = ? AND source_type = 'game_reversed'", targetHash).Scan(&id)
+		var current sql.NullString
+		err = core.DB.QueryRow("SELECT id, code_content FROM native_sources WHERE game = ? AND native_hash = ? AND source_type = 'game_reversed'", game, targetHash).Scan(&id, &current)
 
-		if err == sql.ErrNoRows {
-			_, err = core.DB.Exec("INSERT INTO native_sources (native_hash, code_content, code_lang, source_type, contributor) VALUES (?, ?, 'cpp', 'game_reversed', 'Importer')", targetHash, content)
-			if err == nil {
-				count++
+		switch {
+		case err == sql.ErrNoRows:
+			if dryRun {
+				inserted++
+				break
 			}
-		} else if err == nil {
+			if _, err := core.DB.Exec("INSERT INTO native_sources (game, native_hash, code_content, code_lang, source_type, contributor) VALUES (?, ?, ?, 'cpp', 'game_reversed', 'Importer')", game, targetHash, content); err == nil {
+				inserted++
+			} else {
+				skipped++
+			}
+		case err != nil:
+			skipped++
+		case current.String == content:
+			unchanged++
+		case dryRun:
+			updated++
+		default:
 			var updateSQL string
 			if core.Config.DbType == "sqlite" {
 				updateSQL = "UPDATE native_sources SET code_content = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?"
 			} else {
 				updateSQL = "UPDATE native_sources SET code_content = ?, updated_at = NOW() WHERE id = ?"
 			}
-			_, err = core.DB.Exec(updateSQL, content, id)
-			if err == nil {
-				count++
+			if _, err := core.DB.Exec(updateSQL, content, id); err == nil {
+				updated++
+			} else {
+				skipped++
 			}
-		} else {
-			skipped++
 		}
 
-		if (count+skipped)%100 == 0 {
-			fmt.Printf("Processed sources: %d\r", count+skipped)
+		if processed := inserted + updated + unchanged + skipped; processed%100 == 0 {
+			fmt.Printf("Processed sources: %d\r", processed)
 		}
 	}
 
-	fmt.Printf("\nSources import complete. Processed/Updated: %d\n", count)
+	if dryRun {
+		fmt.Printf("\nDry run finished. Would insert: %d, Would update: %d, Unchanged: %d, Unmatched files: %d, Errors: %d\n", inserted, updated, unchanged, unmatched, skipped)
+		return nil
+	}
+	fmt.Printf("\nSources import complete. Inserted: %d, Updated: %d, Unchanged: %d, Unmatched files: %d, Errors: %d\n", inserted, updated, unchanged, unmatched, skipped)
 	return nil
 }
 
@@ -470,11 +1040,12 @@ func isDir(path string) bool {
 
 /**
  * @brief 构建哈希映射
+ * @param game 游戏
  * @return map[string]string 哈希映射
  * @return error 构建错误
  */
-func buildHashMap() (map[string]string, error) {
-	rows, err := core.DB.Query("SELECT hash, name, jhash FROM natives")
+func buildHashMap(game string) (map[string]string, error) {
+	rows, err := core.DB.Query("SELECT hash, name, jhash FROM natives WHERE game = ?", game)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+
+	"nativedb/internal/core"
+)
+
+type PromptData struct {
+	Locale   string
+	Language string
+	Glossary []core.GlossaryEntry
+	Batch    bool
+}
+
+// defaultPromptTemplate 为默认的系统提示词模板，可通过 ai_prompt_file 覆盖
+const defaultPromptTemplate = `你是一个 FiveM 文档翻译助手。请将输入内容翻译成{{.Language}}（{{.Locale}}）。
+### 输入说明：
+- segments：描述按段落拆分后的内容，键为段落序号，请逐段翻译并使用相同的键返回。
+- params：参数说明，键为参数名。
+- references（可选）：与某段落相似的已审核译文，请参考其措辞与术语保持一致。
+
+### 严格规则：
+1. **输出格式**：必须且只能返回合法的 **JSON** 对象。不要包含任何 Markdown 标记。
+2. **保留格式**：保留 Markdown（代码块、加粗、列表）。
+3. **不要翻译**：参数名、变量名、代码片段。
+4. **逐段对应**：每个段落单独翻译，不要合并、拆分或遗漏段落。
+{{- if .Glossary}}
+5. **术语映射**：
+{{- range .Glossary}}
+   - {{.Term}} -> {{.Translation}}
+{{- end}}
+{{- end}}
+
+### 返回 JSON 结构示例：
+{
+    "segments": {
+        "0": "翻译后的第一段...",
+        "1": "翻译后的第二段..."
+    },
+    "params": {
+        "p0": "翻译后的p0描述...",
+        "modelHash": "翻译后的modelHash描述..."
+    }
+}
+{{- if .Batch}}
+
+### 批量翻译：
+输入为 {"natives": {"函数哈希": {...}}}，每个函数的结构与上述输入相同。
+请返回 {"natives": {"函数哈希": {"segments": {...}, "params": {...}}}}，使用相同的函数哈希作为键，不要遗漏任何函数。
+{{- end}}`
+
+// localeNames 为常见语言代码对应的提示词语言名称
+var localeNames = map[string]string{
+	"zh-CN": "简体中文",
+	"zh-TW": "繁體中文",
+	"zh-HK": "繁體中文（香港）",
+	"ja":    "日语",
+	"ko":    "韩语",
+	"en":    "英语",
+	"ru":    "俄语",
+	"de":    "德语",
+	"fr":    "法语",
+	"es":    "西班牙语",
+	"pt-BR": "巴西葡萄牙语",
+}
+
+/**
+ * @brief 加载系统提示词模板 (默认模板或 ai_prompt_file)
+ * @return *template.Template 提示词模板
+ * @return error 模板读取或解析错误
+ */
+func loadPromptTemplate() (*template.Template, error) {
+	text := defaultPromptTemplate
+	if core.Config.AiPromptFile != "" {
+		content, err := os.ReadFile(core.Config.AiPromptFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt template: %v", err)
+		}
+		text = string(content)
+	}
+
+	tmpl, err := template.New("prompt").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt template: %v", err)
+	}
+	return tmpl, nil
+}
+
+/**
+ * @brief 根据模板生成系统提示词
+ * @param tmpl 提示词模板
+ * @param locale 语言代码
+ * @param glossary 与当前函数相关的术语
+ * @param batch 是否为批量请求
+ * @return string 系统提示词
+ * @return error 模板渲染错误
+ */
+func renderSystemPrompt(tmpl *template.Template, locale string, glossary []core.GlossaryEntry, batch bool) (string, error) {
+	language, ok := localeNames[locale]
+	if !ok {
+		language = locale
+	}
+	data := PromptData{
+		Locale:   locale,
+		Language: language,
+		Glossary: glossary,
+		Batch:    batch,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %v", err)
+	}
+	return buf.String(), nil
+}
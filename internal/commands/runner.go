@@ -0,0 +1,1216 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"nativedb/internal/core"
+	"nativedb/internal/models"
+	"nativedb/internal/translator"
+)
+
+type TranslateTask struct {
+	Game                string
+	Hash                string
+	Name                string
+	DescriptionOriginal string
+	ParamsJSON          []byte
+}
+
+// translationPlan 记录一个函数中命中翻译记忆的部分与需要发送给 AI 的部分
+type translationPlan struct {
+	Source       string
+	NativeParams []models.NativeParam
+	Segments     []string
+	Translated   map[string]string
+	Params       map[string]string
+	Extra        map[string]string
+	Input        translator.Input
+	Description  *string
+	MemoryHits   int
+}
+
+const AITranslatorName = "System_AI"
+
+// maxBatchNatives 为批量模式下单次请求最多包含的函数数量
+const maxBatchNatives = 20
+
+// maxKeptRuns 为服务端保留的历史翻译运行数量
+const maxKeptRuns = 20
+
+const (
+	RunRunning   = "running"
+	RunPaused    = "paused"
+	RunCancelled = "cancelled"
+	RunFinished  = "finished"
+)
+
+// TranslateOptions 为一次翻译运行的筛选条件与参数
+type TranslateOptions struct {
+	Locale           string   `json:"lang"`
+	Namespace        string   `json:"namespace,omitempty"`
+	Hashes           []string `json:"hashes,omitempty"`
+	Limit            int      `json:"limit,omitempty"`
+	BatchTokens      int      `json:"batch_tokens,omitempty"`
+	UntranslatedOnly bool     `json:"untranslated_only,omitempty"`
+	RetryFailed      bool     `json:"retry_failed,omitempty"`
+	IncludeReviewed  bool     `json:"include_reviewed,omitempty"`
+	NoMemory         bool     `json:"no_memory,omitempty"`
+}
+
+// TranslationRunStatus 为翻译运行的进度快照
+type TranslationRunStatus struct {
+	ID         int64            `json:"id"`
+	State      string           `json:"state"`
+	Options    TranslateOptions `json:"options"`
+	Provider   string           `json:"provider"`
+	Total      int              `json:"total"`
+	Processed  int              `json:"processed"`
+	Failed     int              `json:"failed"`
+	Review     int              `json:"review"`
+	MemoryHits int              `json:"memory_hits"`
+	Requests   int              `json:"requests"`
+	Tokens     int              `json:"tokens"`
+	Cost       float64          `json:"cost"`
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+}
+
+// ProgressEvent 为翻译运行的进度事件，Type 为 progress (单个函数完成) 或 state (运行状态变化)
+type ProgressEvent struct {
+	Type   string               `json:"type"`
+	Hash   string               `json:"hash,omitempty"`
+	Name   string               `json:"name,omitempty"`
+	Status string               `json:"status,omitempty"`
+	Run    TranslationRunStatus `json:"run"`
+}
+
+// TranslationRun 为一次翻译运行，CLI 与服务端共用同一个工作线程池
+type TranslationRun struct {
+	ID      int64
+	Options TranslateOptions
+	// OnEvent 在每个进度事件时于工作线程中调用
+	OnEvent func(ProgressEvent)
+
+	locale   string
+	template *template.Template
+	glossary []core.GlossaryEntry
+	provider translator.TranslationProvider
+	memory   *core.TranslationMemory
+	limiter  *translator.RateLimiter
+	report   *translator.CostReport
+
+	total      int
+	processed  atomic.Int32
+	failed     atomic.Int32
+	review     atomic.Int32
+	memoryHits atomic.Int32
+
+	ctx         context.Context
+	cancel      context.CancelFunc
+	mu          sync.Mutex
+	state       string
+	resume      chan struct{}
+	startedAt   time.Time
+	finishedAt  *time.Time
+	subscribers map[chan ProgressEvent]struct{}
+}
+
+// ErrTranslationRunActive 表示已有翻译运行在进行中
+var ErrTranslationRunActive = errors.New("a translation run is already in progress")
+
+var (
+	runsMu    sync.Mutex
+	runs      []*TranslationRun
+	nextRunID int64
+)
+
+/**
+ * @brief 构建待翻译函数的查询条件
+ * @return string FROM ... WHERE 子句
+ * @return []interface{} 查询参数
+ */
+func (o *TranslateOptions) from() (string, []interface{}) {
+	query := `FROM natives n
+		LEFT JOIN native_translations nt ON nt.game = n.game AND nt.native_hash = n.hash AND nt.locale = ?
+		LEFT JOIN native_translation_jobs j ON j.game = n.game AND j.native_hash = n.hash AND j.locale = ?
+		WHERE (COALESCE(nt.status, 0) = 0 OR (nt.outdated = 1 AND nt.status = ?))`
+	args := []interface{}{o.Locale, o.Locale, core.TranslationStatusAI}
+	if o.UntranslatedOnly {
+		query = strings.Replace(query, " OR (nt.outdated = 1 AND nt.status = ?)", "", 1)
+		args = args[:2]
+	} else if o.IncludeReviewed {
+		// 过期的人工审核翻译同样交给 AI 重新翻译
+		query = strings.Replace(query, "(nt.outdated = 1 AND nt.status = ?)", "(nt.outdated = 1 AND nt.status >= ?)", 1)
+	}
+
+	switch {
+	case len(o.Hashes) > 0:
+		// 指定哈希时无论之前是否失败都重新处理
+		query += " AND n.hash IN (?" + strings.Repeat(", ?", len(o.Hashes)-1) + ")"
+		for _, hash := range o.Hashes {
+			args = append(args, hash)
+		}
+	case o.RetryFailed:
+		query += " AND j.status = ?"
+		args = append(args, core.JobFailed)
+	default:
+		// 失败与等待审核的函数不自动重试
+		query += " AND (j.status IS NULL OR j.status NOT IN (?, ?))"
+		args = append(args, core.JobFailed, core.JobReview)
+	}
+	if o.Namespace != "" {
+		query += " AND n.namespace = ?"
+		args = append(args, strings.ToUpper(o.Namespace))
+	}
+	return query, args
+}
+
+/**
+ * @brief 规范化函数哈希 (如 0x4f8644af03d0e0d6 -> 0x4F8644AF03D0E0D6)
+ * @param hash 函数哈希
+ * @return string 规范化后的哈希
+ */
+func normalizeHash(hash string) string {
+	hash = strings.ToUpper(strings.TrimSpace(hash))
+	if strings.HasPrefix(hash, "0X") {
+		return "0x" + hash[2:]
+	}
+	return hash
+}
+
+/**
+ * @brief 根据参数准备翻译运行：加载翻译后端、提示词模板、术语表与翻译记忆，并统计待翻译数量
+ * @param opts 运行参数
+ * @return *TranslationRun 翻译运行
+ * @return error 参数或配置错误
+ */
+func NewTranslationRun(opts TranslateOptions) (*TranslationRun, error) {
+	locale, ok := core.NormalizeLocale(opts.Locale)
+	if !ok {
+		return nil, fmt.Errorf("invalid language code: %s", opts.Locale)
+	}
+	opts.Locale = locale
+	for i, hash := range opts.Hashes {
+		opts.Hashes[i] = normalizeHash(hash)
+	}
+
+	p, err := translator.NewProvider(core.Config)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := loadPromptTemplate()
+	if err != nil {
+		return nil, err
+	}
+	// 先校验模板可以正常渲染，避免每个任务都失败
+	if _, err := renderSystemPrompt(tmpl, locale, nil, opts.BatchTokens > 0); err != nil {
+		return nil, err
+	}
+	entries, err := core.ListGlossary(locale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load glossary: %v", err)
+	}
+
+	r := &TranslationRun{
+		Options:     opts,
+		locale:      locale,
+		template:    tmpl,
+		glossary:    entries,
+		provider:    p,
+		limiter:     translator.NewRateLimiter(core.Config.AiRateRpm, core.Config.AiRateTpm),
+		report:      translator.NewCostReport(core.Config.AiPricePrompt, core.Config.AiPriceCompletion),
+		subscribers: make(map[chan ProgressEvent]struct{}),
+	}
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	if !opts.NoMemory {
+		if core.CountTranslationMemory(locale) == 0 {
+			if _, err := core.RebuildTranslationMemory(locale); err != nil {
+				return nil, fmt.Errorf("failed to build translation memory: %v", err)
+			}
+		}
+		if r.memory, err = core.LoadTranslationMemory(locale); err != nil {
+			return nil, fmt.Errorf("failed to load translation memory: %v", err)
+		}
+	}
+
+	from, fromArgs := opts.from()
+	if err := core.DB.QueryRow("SELECT COUNT(*) "+from, fromArgs...).Scan(&r.total); err != nil {
+		return nil, fmt.Errorf("failed to count pending tasks: %v", err)
+	}
+	if opts.Limit > 0 && r.total > opts.Limit {
+		r.total = opts.Limit
+	}
+	return r, nil
+}
+
+/**
+ * @brief 获取待翻译的函数数量
+ * @return int 数量
+ */
+func (r *TranslationRun) Total() int {
+	return r.total
+}
+
+/**
+ * @brief 获取翻译后端名称
+ * @return string 名称
+ */
+func (r *TranslationRun) ProviderName() string {
+	return r.provider.Name()
+}
+
+/**
+ * @brief 获取翻译记忆条目数量
+ * @return int 条目数量，未使用翻译记忆时为 -1
+ */
+func (r *TranslationRun) MemorySize() int {
+	if r.memory == nil {
+		return -1
+	}
+	return r.memory.Len()
+}
+
+/**
+ * @brief 获取费用报告
+ * @return string 多行报告
+ */
+func (r *TranslationRun) Summary() string {
+	return r.report.Summary()
+}
+
+/**
+ * @brief 执行翻译，阻塞直到全部完成或被取消
+ */
+func (r *TranslationRun) Run() {
+	r.mu.Lock()
+	if r.state == "" {
+		r.state = RunRunning
+		r.startedAt = time.Now()
+	}
+	r.mu.Unlock()
+	defer r.cancel()
+	r.emit(ProgressEvent{Type: "state"})
+
+	workerCount := core.Config.AiWorkers
+	tasks := make(chan []TranslateTask, workerCount*2)
+	var wg sync.WaitGroup
+
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go r.worker(tasks, &wg)
+	}
+
+	go r.feed(tasks)
+	wg.Wait()
+
+	r.mu.Lock()
+	if r.state != RunCancelled {
+		r.state = RunFinished
+	}
+	now := time.Now()
+	r.finishedAt = &now
+	r.mu.Unlock()
+	r.emit(ProgressEvent{Type: "state"})
+
+	r.mu.Lock()
+	for ch := range r.subscribers {
+		close(ch)
+	}
+	r.subscribers = make(map[chan ProgressEvent]struct{})
+	r.mu.Unlock()
+}
+
+/**
+ * @brief 按游戏与哈希顺序分批读取待翻译函数并分发给工作线程
+ * @param tasks 任务通道
+ */
+func (r *TranslationRun) feed(tasks chan<- []TranslateTask) {
+	defer close(tasks)
+	from, fromArgs := r.Options.from()
+	// 先读完整批再分发，避免 SQLite 单连接下游标阻塞写入
+	lastGame, lastHash := "", ""
+	remaining := r.total
+	for remaining > 0 {
+		batchSize := min(100, remaining)
+		queryArgs := append(append([]interface{}{}, fromArgs...), lastGame, lastGame, lastHash, batchSize)
+		rows, err := core.DB.Query("SELECT n.game, n.hash, n.name, n.description_original, n.params "+from+" AND (n.game > ? OR (n.game = ? AND n.hash > ?)) ORDER BY n.game ASC, n.hash ASC LIMIT ?", queryArgs...)
+		if err != nil {
+			log.Printf("\nDB Query Error: %v", err)
+			return
+		}
+
+		var batch []TranslateTask
+		for rows.Next() {
+			var t TranslateTask
+			var paramsRaw []byte
+			if err := rows.Scan(&t.Game, &t.Hash, &t.Name, &t.DescriptionOriginal, &paramsRaw); err != nil {
+				continue
+			}
+			if len(paramsRaw) == 0 {
+				t.ParamsJSON = []byte("[]")
+			} else {
+				t.ParamsJSON = paramsRaw
+			}
+			batch = append(batch, t)
+		}
+		rows.Close()
+
+		if len(batch) == 0 {
+			return
+		}
+		for _, group := range groupTasks(batch, r.Options.BatchTokens) {
+			select {
+			case tasks <- group:
+			case <-r.ctx.Done():
+				return
+			}
+		}
+		lastGame, lastHash = batch[len(batch)-1].Game, batch[len(batch)-1].Hash
+		remaining -= len(batch)
+	}
+}
+
+/**
+ * @brief 翻译任务处理函数
+ * @param tasks 任务通道，每项为一组任务
+ * @param wg 等待组
+ */
+func (r *TranslationRun) worker(tasks <-chan []TranslateTask, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for group := range tasks {
+		// 取消后仅清空通道，不再处理剩余任务
+		if !r.wait() {
+			continue
+		}
+		if len(group) == 1 {
+			r.processTask(group[0])
+		} else {
+			r.processBatch(group)
+		}
+	}
+}
+
+/**
+ * @brief 暂停时阻塞直到恢复或取消
+ * @return bool 是否可以继续处理
+ */
+func (r *TranslationRun) wait() bool {
+	for {
+		r.mu.Lock()
+		resume := r.resume
+		r.mu.Unlock()
+		if resume == nil {
+			return r.ctx.Err() == nil
+		}
+		select {
+		case <-resume:
+		case <-r.ctx.Done():
+			return false
+		}
+	}
+}
+
+/**
+ * @brief 暂停翻译，正在处理的函数会继续完成
+ * @return error 运行不处于进行中时返回错误
+ */
+func (r *TranslationRun) Pause() error {
+	r.mu.Lock()
+	if r.state != RunRunning {
+		r.mu.Unlock()
+		return fmt.Errorf("translation run is %s", r.state)
+	}
+	r.state = RunPaused
+	r.resume = make(chan struct{})
+	r.mu.Unlock()
+	r.emit(ProgressEvent{Type: "state"})
+	return nil
+}
+
+/**
+ * @brief 恢复已暂停的翻译
+ * @return error 运行未暂停时返回错误
+ */
+func (r *TranslationRun) Resume() error {
+	r.mu.Lock()
+	if r.state != RunPaused {
+		r.mu.Unlock()
+		return fmt.Errorf("translation run is %s", r.state)
+	}
+	r.state = RunRunning
+	close(r.resume)
+	r.resume = nil
+	r.mu.Unlock()
+	r.emit(ProgressEvent{Type: "state"})
+	return nil
+}
+
+/**
+ * @brief 取消翻译，正在等待限流、重试或请求中的函数会立即中止，未完成的函数保持待翻译状态
+ * @return error 运行已结束时返回错误
+ */
+func (r *TranslationRun) Cancel() error {
+	r.mu.Lock()
+	if r.state != RunRunning && r.state != RunPaused {
+		r.mu.Unlock()
+		return fmt.Errorf("translation run is %s", r.state)
+	}
+	r.state = RunCancelled
+	r.mu.Unlock()
+	r.cancel()
+	r.emit(ProgressEvent{Type: "state"})
+	return nil
+}
+
+/**
+ * @brief 获取进度快照
+ * @return TranslationRunStatus 进度
+ */
+func (r *TranslationRun) Status() TranslationRunStatus {
+	r.mu.Lock()
+	state, startedAt, finishedAt := r.state, r.startedAt, r.finishedAt
+	r.mu.Unlock()
+	return TranslationRunStatus{
+		ID:         r.ID,
+		State:      state,
+		Options:    r.Options,
+		Provider:   r.provider.Name(),
+		Total:      r.total,
+		Processed:  int(r.processed.Load()),
+		Failed:     int(r.failed.Load()),
+		Review:     int(r.review.Load()),
+		MemoryHits: int(r.memoryHits.Load()),
+		Requests:   r.report.RequestCount(),
+		Tokens:     r.report.TotalTokens(),
+		Cost:       r.report.Cost(),
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+	}
+}
+
+/**
+ * @brief 订阅进度事件，运行结束时通道被关闭
+ * @return <-chan ProgressEvent 事件通道
+ * @return func() 取消订阅
+ */
+func (r *TranslationRun) Subscribe() (<-chan ProgressEvent, func()) {
+	ch := make(chan ProgressEvent, 64)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.finishedAt != nil {
+		close(ch)
+		return ch, func() {}
+	}
+	r.subscribers[ch] = struct{}{}
+	return ch, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if _, ok := r.subscribers[ch]; ok {
+			delete(r.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+/**
+ * @brief 分发进度事件，订阅者处理不及时时丢弃事件
+ * @param e 进度事件
+ */
+func (r *TranslationRun) emit(e ProgressEvent) {
+	e.Run = r.Status()
+	if r.OnEvent != nil {
+		r.OnEvent(e)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for ch := range r.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+/**
+ * @brief 记录单个函数的处理结果
+ * @param task 翻译任务
+ * @param status 结果 (OK、FAILED、REVIEW、SKIPPED、MEMORY、BATCH)
+ */
+func (r *TranslationRun) progress(task TranslateTask, status string) {
+	r.processed.Add(1)
+	r.emit(ProgressEvent{Type: "progress", Hash: task.Hash, Name: task.Name, Status: status})
+}
+
+/**
+ * @brief 在后台启动翻译运行 (同一时间只允许一个运行)
+ * @param opts 运行参数
+ * @param onEvent 进度事件回调，可为 nil
+ * @return *TranslationRun 翻译运行
+ * @return error 已有运行进行中或参数错误
+ */
+func StartTranslationRun(opts TranslateOptions, onEvent func(ProgressEvent)) (*TranslationRun, error) {
+	runsMu.Lock()
+	err := activeRunError()
+	runsMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// 准备运行可能需要重建翻译记忆，不持有锁，避免阻塞运行列表查询
+	run, err := NewTranslationRun(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	runsMu.Lock()
+	defer runsMu.Unlock()
+	// 准备期间可能已有其他运行启动
+	if err := activeRunError(); err != nil {
+		return nil, err
+	}
+	nextRunID++
+	run.ID = nextRunID
+	run.OnEvent = onEvent
+	run.state = RunRunning
+	run.startedAt = time.Now()
+
+	runs = append(runs, run)
+	if len(runs) > maxKeptRuns {
+		runs = runs[len(runs)-maxKeptRuns:]
+	}
+	go run.Run()
+	return run, nil
+}
+
+/**
+ * @brief 检查是否已有进行中或暂停的运行，调用方需持有 runsMu
+ * @return error 已有运行时返回 ErrTranslationRunActive
+ */
+func activeRunError() error {
+	for _, run := range runs {
+		if state := run.Status().State; state == RunRunning || state == RunPaused {
+			return fmt.Errorf("%w (#%d, %s)", ErrTranslationRunActive, run.ID, state)
+		}
+	}
+	return nil
+}
+
+/**
+ * @brief 按 ID 查找翻译运行
+ * @param id 运行 ID
+ * @return *TranslationRun 翻译运行，不存在时返回 nil
+ */
+func GetTranslationRun(id int64) *TranslationRun {
+	runsMu.Lock()
+	defer runsMu.Unlock()
+	for _, run := range runs {
+		if run.ID == id {
+			return run
+		}
+	}
+	return nil
+}
+
+/**
+ * @brief 列出最近的翻译运行
+ * @return []TranslationRunStatus 进度列表 (新的在前)
+ */
+func ListTranslationRuns() []TranslationRunStatus {
+	runsMu.Lock()
+	defer runsMu.Unlock()
+	list := make([]TranslationRunStatus, 0, len(runs))
+	for i := len(runs) - 1; i >= 0; i-- {
+		list = append(list, runs[i].Status())
+	}
+	return list
+}
+
+/**
+ * @brief 按 token 预算将任务分组，超出预算的函数单独成组
+ * @param batch 任务列表
+ * @param budget 每组的输入 token 预算，0 表示不分组
+ * @return [][]TranslateTask 任务分组
+ */
+func groupTasks(batch []TranslateTask, budget int) [][]TranslateTask {
+	var groups [][]TranslateTask
+	var current []TranslateTask
+	used := 0
+	for _, t := range batch {
+		cost := estimateTaskTokens(t)
+		if budget <= 0 || cost >= budget {
+			groups = append(groups, []TranslateTask{t})
+			continue
+		}
+		if len(current) > 0 && (used+cost > budget || len(current) >= maxBatchNatives) {
+			groups = append(groups, current)
+			current, used = nil, 0
+		}
+		current = append(current, t)
+		used += cost
+	}
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+	return groups
+}
+
+/**
+ * @brief 根据翻译记忆拆分任务，得到已命中的译文与需要发送给 AI 的部分
+ * @param task 翻译任务
+ * @param params 参数列表
+ * @return *translationPlan 翻译计划
+ */
+func (r *TranslationRun) planTranslation(task TranslateTask, params []models.NativeParam) *translationPlan {
+	plan := &translationPlan{
+		Source:       task.DescriptionOriginal,
+		NativeParams: params,
+		Segments:     core.SegmentText(task.DescriptionOriginal),
+		Translated:   make(map[string]string),
+		Params:       make(map[string]string),
+		Input: translator.Input{
+			NativeName: task.Name,
+			Segments:   make(map[string]string),
+			Params:     make(map[string]string),
+		},
+	}
+
+	for i, seg := range plan.Segments {
+		key := strconv.Itoa(i)
+		if r.memory != nil {
+			if target, ok := r.memory.Lookup(seg); ok {
+				plan.Translated[key] = target
+				plan.MemoryHits++
+				continue
+			}
+			if ref, _ := r.memory.Fuzzy(seg, core.Config.AiMemoryFuzzy); ref != nil {
+				if plan.Input.References == nil {
+					plan.Input.References = make(map[string]translator.Reference)
+				}
+				plan.Input.References[key] = translator.Reference{Source: ref.Source, Translation: ref.Target}
+			}
+		}
+		plan.Input.Segments[key] = seg
+	}
+
+	for _, p := range params {
+		if strings.TrimSpace(p.Description) == "" {
+			continue
+		}
+		if r.memory != nil {
+			if target, ok := r.memory.Lookup(p.Description); ok {
+				plan.Params[p.Name] = target
+				plan.MemoryHits++
+				continue
+			}
+		}
+		plan.Input.Params[p.Name] = p.Description
+	}
+	return plan
+}
+
+/**
+ * @brief 合并 AI 返回的译文，先清除上一次返回的结果 (批量结果未通过时回退为单个请求，或重试时复用同一计划)
+ * @param output AI 返回结果
+ * @return error 缺少段落译文时返回错误
+ */
+func (plan *translationPlan) merge(output *translator.Output) error {
+	plan.reset()
+	if output.Params == nil {
+		output.Params = output.ParamsCn
+	}
+	if len(output.Segments) == 0 {
+		// 兼容只返回整段 description 的自定义提示词
+		desc := output.Description
+		if desc == "" {
+			desc = output.DescriptionCn
+		}
+		if desc != "" && len(plan.Input.Segments) == len(plan.Segments) {
+			plan.Description = &desc
+		} else if desc != "" && len(plan.Input.Segments) == 1 {
+			for key := range plan.Input.Segments {
+				output.Segments = map[string]string{key: desc}
+			}
+		}
+	}
+
+	if plan.Description == nil {
+		for key := range plan.Input.Segments {
+			text := strings.TrimSpace(output.Segments[key])
+			if text == "" {
+				return fmt.Errorf("missing translation for segment %s", key)
+			}
+			plan.Translated[key] = text
+		}
+	}
+	for name := range plan.Input.Params {
+		if text := output.Params[name]; text != "" {
+			plan.Params[name] = text
+		}
+	}
+
+	// 记录模型返回的不存在的参数，交由质检处理
+	plan.Extra = make(map[string]string)
+	for name, text := range output.Params {
+		if !plan.hasParam(name) {
+			plan.Extra[name] = text
+		}
+	}
+	return nil
+}
+
+/**
+ * @brief 清除 AI 返回的译文，只保留命中翻译记忆的部分
+ */
+func (plan *translationPlan) reset() {
+	plan.Description = nil
+	for key := range plan.Input.Segments {
+		delete(plan.Translated, key)
+	}
+	for name := range plan.Input.Params {
+		delete(plan.Params, name)
+	}
+	plan.Extra = nil
+}
+
+/**
+ * @brief 判断函数是否有指定名称的参数
+ * @param name 参数名
+ * @return bool 是否存在
+ */
+func (plan *translationPlan) hasParam(name string) bool {
+	for _, p := range plan.NativeParams {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * @brief 对合并后的译文进行自动质检
+ * @param locale 目标语言
+ * @return []core.QAIssue 发现的问题，为空表示通过
+ */
+func (plan *translationPlan) validate(locale string) []core.QAIssue {
+	checked := make(map[string]string, len(plan.Params)+len(plan.Extra))
+	for name, text := range plan.Params {
+		checked[name] = text
+	}
+	for name, text := range plan.Extra {
+		checked[name] = text
+	}
+	return core.ValidateTranslation(locale, plan.Source, plan.NativeParams, plan.description(), checked)
+}
+
+/**
+ * @brief 按段落顺序拼接完整的描述译文
+ * @return string 描述译文
+ */
+func (plan *translationPlan) description() string {
+	if plan.Description != nil {
+		return *plan.Description
+	}
+	parts := make([]string, 0, len(plan.Segments))
+	for i := range plan.Segments {
+		parts = append(parts, plan.Translated[strconv.Itoa(i)])
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+/**
+ * @brief 预处理翻译任务：跳过无需翻译的函数，并直接写入完全命中翻译记忆的函数
+ * @param task 翻译任务
+ * @return *translationPlan 仍需调用 AI 的翻译计划，任务已完成时返回 nil
+ */
+func (r *TranslationRun) prepareTask(task TranslateTask) *translationPlan {
+	var params []models.NativeParam
+	if err := json.Unmarshal(task.ParamsJSON, &params); err != nil {
+		params = []models.NativeParam{}
+	}
+
+	hasDesc := len(strings.TrimSpace(task.DescriptionOriginal)) > 1
+	hasParamDesc := false
+	for _, p := range params {
+		if len(strings.TrimSpace(p.Description)) > 0 {
+			hasParamDesc = true
+			break
+		}
+	}
+
+	if !hasDesc && !hasParamDesc {
+		markAsTranslated(task.Game, task.Hash, r.locale)
+		core.FinishTranslationJob(task.Game, task.Hash, r.locale, core.JobDone, 0, 0, "")
+		r.progress(task, "SKIPPED")
+		return nil
+	}
+
+	core.StartTranslationJob(task.Game, task.Hash, r.locale)
+	plan := r.planTranslation(task, params)
+	r.memoryHits.Add(int32(plan.MemoryHits))
+
+	// 所有段落与参数均命中翻译记忆时无需调用 AI
+	if len(plan.Input.Segments) == 0 && len(plan.Input.Params) == 0 {
+		if err := updateDatabase(task.Game, task.Hash, r.locale, plan.description(), plan.Params); err != nil {
+			r.failed.Add(1)
+			core.FinishTranslationJob(task.Game, task.Hash, r.locale, core.JobFailed, 0, 0, err.Error())
+			r.progress(task, "FAILED")
+			return nil
+		}
+		core.FinishTranslationJob(task.Game, task.Hash, r.locale, core.JobDone, 0, 0, "")
+		r.progress(task, "MEMORY")
+		return nil
+	}
+	return plan
+}
+
+/**
+ * @brief 处理单个翻译任务
+ * @param task 翻译任务
+ */
+func (r *TranslationRun) processTask(task TranslateTask) {
+	if plan := r.prepareTask(task); plan != nil {
+		r.translateSingle(task, plan)
+	}
+}
+
+/**
+ * @brief 等待重试间隔，运行被取消时立即返回
+ * @param d 等待时间
+ * @return bool 是否等待完毕，被取消时返回 false
+ */
+func (r *TranslationRun) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-r.ctx.Done():
+		return false
+	}
+}
+
+/**
+ * @brief 运行被取消时放弃任务，任务恢复为待翻译，已消耗的尝试次数与 token 仍然记录
+ * @param task 翻译任务
+ * @param attempts 尝试次数
+ * @param tokens 消耗的 token
+ */
+func (r *TranslationRun) abandon(task TranslateTask, attempts, tokens int) {
+	core.FinishTranslationJob(task.Game, task.Hash, r.locale, core.JobPending, attempts, tokens, "")
+	r.emit(ProgressEvent{Type: "progress", Hash: task.Hash, Name: task.Name, Status: "CANCELLED"})
+}
+
+/**
+ * @brief 以单个请求翻译一个函数，失败时最多重试 3 次
+ * @param task 翻译任务
+ * @param plan 翻译计划
+ */
+func (r *TranslationRun) translateSingle(task TranslateTask, plan *translationPlan) {
+	attempts, tokens := 0, 0
+	var lastErr error
+	var issues []core.QAIssue
+	for retry := 0; retry < 3; retry++ {
+		attempts++
+		issues = nil
+		resultJSON, usage, err := r.callAI(&plan.Input)
+		tokens += usage.TotalTokens
+		if err != nil {
+			lastErr = err
+			if !translator.Retryable(err) || retry == 2 {
+				break
+			}
+			r.report.AddRetry()
+			if !r.sleep(translator.Backoff(retry, err)) {
+				break
+			}
+			continue
+		}
+
+		var output translator.Output
+		if err := json.Unmarshal([]byte(cleanCodeBlock(resultJSON)), &output); err != nil {
+			lastErr = err
+			continue
+		}
+		if err := plan.merge(&output); err != nil {
+			lastErr = err
+			continue
+		}
+		if issues = plan.validate(r.locale); len(issues) > 0 {
+			lastErr = fmt.Errorf("quality check failed: %d issues", len(issues))
+			continue
+		}
+
+		if err := updateDatabase(task.Game, task.Hash, r.locale, plan.description(), plan.Params); err != nil {
+			lastErr = err
+			break
+		}
+		core.FinishTranslationJob(task.Game, task.Hash, r.locale, core.JobDone, attempts, tokens, "")
+		r.progress(task, "OK")
+		return
+	}
+
+	if r.ctx.Err() != nil {
+		r.abandon(task, attempts, tokens)
+		return
+	}
+
+	// 最后一次结果未通过质检时提交审核，而不是标记为已翻译
+	if len(issues) > 0 {
+		err := core.QueueTranslationReview(task.Game, task.Hash, r.locale, plan.description(), plan.Params, AITranslatorName, issues)
+		if err == nil {
+			r.review.Add(1)
+			core.FinishTranslationJob(task.Game, task.Hash, r.locale, core.JobReview, attempts, tokens, core.FormatQAIssues(issues))
+			r.progress(task, "REVIEW")
+			return
+		}
+		lastErr = err
+	}
+
+	r.failed.Add(1)
+	core.FinishTranslationJob(task.Game, task.Hash, r.locale, core.JobFailed, attempts, tokens, lastErr.Error())
+	r.progress(task, "FAILED")
+	log.Printf("Translation failed for %s: %v", task.Name, lastErr)
+}
+
+/**
+ * @brief 将多个函数合并为一个请求翻译，批量结果无法解析或缺失的函数回退为单个请求
+ * @param batch 翻译任务列表
+ */
+func (r *TranslationRun) processBatch(batch []TranslateTask) {
+	var tasks []TranslateTask
+	var plans []*translationPlan
+	for _, task := range batch {
+		if plan := r.prepareTask(task); plan != nil {
+			tasks = append(tasks, task)
+			plans = append(plans, plan)
+		}
+	}
+	if len(tasks) == 0 {
+		return
+	}
+	if len(tasks) == 1 {
+		r.translateSingle(tasks[0], plans[0])
+		return
+	}
+
+	input := translator.BatchInput{Natives: make(map[string]translator.Input, len(tasks))}
+	for i, task := range tasks {
+		input.Natives[task.Hash] = plans[i].Input
+	}
+	resultJSON, usage, err := r.callAIBatch(&input)
+
+	var output translator.BatchOutput
+	if err == nil {
+		err = json.Unmarshal([]byte(cleanCodeBlock(resultJSON)), &output)
+	}
+	if r.ctx.Err() != nil {
+		for _, task := range tasks {
+			r.abandon(task, 0, usage.TotalTokens/len(tasks))
+		}
+		return
+	}
+	if err != nil {
+		log.Printf("Batch of %d natives failed, falling back to single requests: %v", len(tasks), err)
+	}
+
+	// 批量请求的 token 用量平均分摊到各个函数
+	share := usage.TotalTokens / len(tasks)
+	for i, task := range tasks {
+		plan := plans[i]
+		result, ok := output.Natives[task.Hash]
+		// 批量结果缺失、不完整或未通过质检的函数单独重新翻译
+		if !ok || plan.merge(&result) != nil || len(plan.validate(r.locale)) > 0 {
+			r.translateSingle(task, plan)
+			continue
+		}
+		if err := updateDatabase(task.Game, task.Hash, r.locale, plan.description(), plan.Params); err != nil {
+			r.failed.Add(1)
+			core.FinishTranslationJob(task.Game, task.Hash, r.locale, core.JobFailed, 1, share, err.Error())
+			r.progress(task, "FAILED")
+			continue
+		}
+		core.FinishTranslationJob(task.Game, task.Hash, r.locale, core.JobDone, 1, share, "")
+		r.progress(task, "BATCH")
+	}
+}
+
+/**
+ * @brief 估算单个函数翻译请求的输入 token 数
+ * @param task 翻译任务
+ * @return int token 数
+ */
+func estimateTaskTokens(task TranslateTask) int {
+	return translator.EstimateTokens(task.Name) + translator.EstimateTokens(task.DescriptionOriginal) + translator.EstimateTokens(string(task.ParamsJSON))
+}
+
+/**
+ * @brief 调用翻译后端翻译单个函数
+ * @param input 需要翻译的段落与参数
+ * @return string 翻译后的 JSON 字符串
+ * @return translator.Usage token 用量
+ * @return error 调用错误
+ */
+func (r *TranslationRun) callAI(input *translator.Input) (string, translator.Usage, error) {
+	return r.sendTranslation(input, inputSourceText(input), false)
+}
+
+/**
+ * @brief 调用翻译后端批量翻译多个函数
+ * @param input 以函数哈希为键的批量输入
+ * @return string 翻译后的 JSON 字符串
+ * @return translator.Usage token 用量
+ * @return error 调用错误
+ */
+func (r *TranslationRun) callAIBatch(input *translator.BatchInput) (string, translator.Usage, error) {
+	sourceParts := make([]string, 0, len(input.Natives))
+	for _, native := range input.Natives {
+		sourceParts = append(sourceParts, inputSourceText(&native))
+	}
+	return r.sendTranslation(input, strings.Join(sourceParts, "\n"), true)
+}
+
+/**
+ * @brief 拼接需要翻译的原文，用于匹配术语
+ * @param input 需要翻译的段落与参数
+ * @return string 原文
+ */
+func inputSourceText(input *translator.Input) string {
+	sourceParts := make([]string, 0, len(input.Segments)+len(input.Params))
+	for _, text := range input.Segments {
+		sourceParts = append(sourceParts, text)
+	}
+	for _, text := range input.Params {
+		sourceParts = append(sourceParts, text)
+	}
+	return strings.Join(sourceParts, "\n")
+}
+
+/**
+ * @brief 渲染系统提示词并发送翻译请求
+ * @param payload 输入数据
+ * @param sourceText 原文，仅注入其中出现的术语
+ * @param batch 是否为批量请求
+ * @return string 翻译后的 JSON 字符串
+ * @return translator.Usage token 用量
+ * @return error 调用错误
+ */
+func (r *TranslationRun) sendTranslation(payload interface{}, sourceText string, batch bool) (string, translator.Usage, error) {
+	inputJSON, _ := json.Marshal(payload)
+
+	systemPrompt, err := renderSystemPrompt(r.template, r.locale, core.MatchGlossary(r.glossary, sourceText), batch)
+	if err != nil {
+		return "", translator.Usage{}, err
+	}
+
+	// 按输入长度预估 token 数 (输出约与输入相当)，收到响应后按实际用量修正；取消运行会中止限流等待与请求
+	ctx := r.ctx
+	estimated := 2 * (translator.EstimateTokens(systemPrompt) + translator.EstimateTokens(string(inputJSON)))
+	waited, err := r.limiter.Wait(ctx, estimated)
+	r.report.AddWait(waited)
+	if err != nil {
+		return "", translator.Usage{}, err
+	}
+
+	resp, err := r.provider.Translate(ctx, translator.Request{
+		Locale:       r.locale,
+		SystemPrompt: systemPrompt,
+		Input:        string(inputJSON),
+		Temperature:  0.1,
+		MaxTokens:    4096,
+	})
+	if err != nil {
+		r.report.AddRequest(translator.Usage{}, err)
+		return "", translator.Usage{}, err
+	}
+
+	usage := resp.Usage
+	if usage.TotalTokens == 0 {
+		// 接口未返回 usage 时按文本长度估算
+		usage.PromptTokens = translator.EstimateTokens(systemPrompt) + translator.EstimateTokens(string(inputJSON))
+		usage.CompletionTokens = translator.EstimateTokens(resp.Content)
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	r.limiter.Adjust(usage.TotalTokens - estimated)
+	r.report.AddRequest(usage, nil)
+	return resp.Content, usage, nil
+}
+
+/**
+ * @brief 更新数据库中的翻译结果
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param locale 语言代码
+ * @param desc 翻译后的描述
+ * @param translatedParams 参数名到翻译的映射
+ * @return error 更新错误
+ */
+func updateDatabase(game, hash, locale, desc string, translatedParams map[string]string) error {
+	t, err := core.GetTranslation(game, hash, locale)
+	if err != nil {
+		return err
+	}
+	oldDesc := t.Description
+	oldParams, _ := json.Marshal(t.Params)
+
+	t.Description = desc
+	for name, text := range translatedParams {
+		t.Params[name] = text
+	}
+	t.Status = core.TranslationStatusAI
+	if err := core.SaveTranslation(t); err != nil {
+		return err
+	}
+
+	newParams, _ := json.Marshal(t.Params)
+	if err := core.RecordRevision(game, hash, core.DescriptionField(t.Locale), oldDesc, desc, AITranslatorName); err != nil {
+		return err
+	}
+	if err := core.RecordRevision(game, hash, core.ParamsField(t.Locale), string(oldParams), string(newParams), AITranslatorName); err != nil {
+		return err
+	}
+	return core.SyncSearchIndex(game, hash)
+}
+
+/**
+ * @brief 标记无需翻译的函数为已翻译
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param locale 语言代码
+ */
+func markAsTranslated(game, hash, locale string) {
+	t, err := core.GetTranslation(game, hash, locale)
+	if err != nil {
+		return
+	}
+	t.Status = core.TranslationStatusAI
+	core.SaveTranslation(t)
+}
+
+/**
+ * @brief 清理代码块中的 JSON 字符串
+ * @param s 包含 JSON 代码块的字符串
+ * @return string 清理后的 JSON 字符串
+ */
+func cleanCodeBlock(s string) string {
+	start := strings.Index(s, "{")
+	end := strings.LastIndex(s, "}")
+	if start != -1 && end != -1 && end > start {
+		return s[start : end+1]
+	}
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "```json")
+	s = strings.TrimPrefix(s, "```")
+	s = strings.TrimSuffix(s, "```")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,275 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"nativedb/internal/core"
+	"nativedb/internal/translator"
+)
+
+const testLocale = "fr"
+
+// scriptedProvider 在 MockProvider 的基础上模拟异常输出：原样返回指定函数的原文，并从批量结果中丢弃指定函数
+type scriptedProvider struct {
+	translator.MockProvider
+	echo string
+	drop string
+}
+
+/**
+ * @brief 生成翻译结果，按配置篡改 MockProvider 的输出
+ * @param ctx 上下文
+ * @param r 翻译请求
+ * @return *translator.Response 翻译结果
+ * @return error 调用错误
+ */
+func (p *scriptedProvider) Translate(ctx context.Context, r translator.Request) (*translator.Response, error) {
+	resp, err := p.MockProvider.Translate(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var batchIn translator.BatchInput
+	if err := json.Unmarshal([]byte(r.Input), &batchIn); err == nil && len(batchIn.Natives) > 0 {
+		var out translator.BatchOutput
+		if err := json.Unmarshal([]byte(resp.Content), &out); err != nil {
+			return nil, err
+		}
+		for hash, in := range batchIn.Natives {
+			if in.NativeName == p.drop {
+				delete(out.Natives, hash)
+			} else if in.NativeName == p.echo {
+				out.Natives[hash] = echoOutput(in)
+			}
+		}
+		content, _ := json.Marshal(out)
+		resp.Content = string(content)
+		return resp, nil
+	}
+
+	var in translator.Input
+	if err := json.Unmarshal([]byte(r.Input), &in); err != nil {
+		return nil, err
+	}
+	if in.NativeName == p.echo {
+		content, _ := json.Marshal(echoOutput(in))
+		resp.Content = string(content)
+	}
+	return resp, nil
+}
+
+/**
+ * @brief 构造原样返回原文的译文
+ * @param in 函数输入
+ * @return translator.Output 译文
+ */
+func echoOutput(in translator.Input) translator.Output {
+	return translator.Output{Segments: in.Segments, Params: in.Params}
+}
+
+/**
+ * @brief 初始化内存 SQLite 数据库并写入测试函数
+ * @param t 测试上下文
+ */
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	core.Config = &core.AppConfig{
+		DbType:       "sqlite",
+		SqliteDbPath: ":memory:",
+		AiProvider:   translator.ProviderMock,
+		AiWorkers:    2,
+	}
+	core.InitDB(core.Config)
+	t.Cleanup(func() { core.DB.Close() })
+
+	natives := []struct {
+		hash, name, desc, params string
+	}{
+		{"0x0000000000000001", "GET_PLAYER_PED", "Returns the ped handle of the given player.", `[{"name":"playerId","type":"Player","description":"The player index to look up."}]`},
+		{"0x0000000000000002", "WAIT", "", `[]`},
+		{"0x0000000000000003", "SET_ENTITY_HEALTH", "Sets the health of the entity to the given value.", `[]`},
+		{"0x0000000000000004", "GET_ENTITY_COORDS", "Gets the world position of an entity.", `[{"name":"entity","type":"Entity","description":"The entity to query."}]`},
+	}
+	for _, n := range natives {
+		if _, err := core.DB.Exec(`INSERT INTO natives (hash, name, namespace, params, description_original) VALUES (?, ?, 'TEST', ?, ?)`,
+			n.hash, n.name, n.params, n.desc); err != nil {
+			t.Fatalf("insert native %s: %v", n.name, err)
+		}
+	}
+}
+
+/**
+ * @brief 执行一次翻译运行直到结束
+ * @param t 测试上下文
+ * @param opts 运行参数
+ * @param provider 翻译后端
+ * @return *TranslationRun 已结束的翻译运行
+ */
+func runTranslation(t *testing.T, opts TranslateOptions, provider translator.TranslationProvider) *TranslationRun {
+	t.Helper()
+	run, err := NewTranslationRun(opts)
+	if err != nil {
+		t.Fatalf("NewTranslationRun: %v", err)
+	}
+	run.provider = provider
+	run.Run()
+	return run
+}
+
+/**
+ * @brief 按函数哈希读取翻译任务
+ * @param t 测试上下文
+ * @return map[string]core.TranslationJob 函数哈希到任务的映射
+ */
+func jobsByHash(t *testing.T) map[string]core.TranslationJob {
+	t.Helper()
+	jobs, err := core.ListTranslationJobs(testLocale, "")
+	if err != nil {
+		t.Fatalf("ListTranslationJobs: %v", err)
+	}
+	result := make(map[string]core.TranslationJob, len(jobs))
+	for _, j := range jobs {
+		result[j.NativeHash] = j
+	}
+	return result
+}
+
+/**
+ * @brief 检查 AI 译文已写入
+ * @param t 测试上下文
+ * @param hash 函数哈希
+ * @param desc 期望的描述译文
+ * @param params 期望的参数译文
+ */
+func assertTranslated(t *testing.T, hash, desc string, params map[string]string) {
+	t.Helper()
+	tr, err := core.GetTranslation(core.DefaultGame, hash, testLocale)
+	if err != nil {
+		t.Fatalf("GetTranslation %s: %v", hash, err)
+	}
+	if tr.Status != core.TranslationStatusAI {
+		t.Errorf("%s: status = %d, want %d", hash, tr.Status, core.TranslationStatusAI)
+	}
+	if tr.Description != desc {
+		t.Errorf("%s: description = %q, want %q", hash, tr.Description, desc)
+	}
+	for name, text := range params {
+		if tr.Params[name] != text {
+			t.Errorf("%s: params[%s] = %q, want %q", hash, name, tr.Params[name], text)
+		}
+	}
+}
+
+/**
+ * @brief 检查翻译任务的状态、尝试次数与 token 用量
+ * @param t 测试上下文
+ * @param jobs 任务映射
+ * @param hash 函数哈希
+ * @param status 期望状态
+ * @param attempts 期望尝试次数
+ * @param withTokens 是否应记录 token 用量
+ */
+func assertJob(t *testing.T, jobs map[string]core.TranslationJob, hash, status string, attempts int, withTokens bool) {
+	t.Helper()
+	j, ok := jobs[hash]
+	if !ok {
+		t.Fatalf("%s: no job row", hash)
+	}
+	if j.Status != status || j.Attempts != attempts {
+		t.Errorf("%s: job = %s/%d attempts, want %s/%d", hash, j.Status, j.Attempts, status, attempts)
+	}
+	if withTokens && j.TokensUsed <= 0 {
+		t.Errorf("%s: tokens_used = %d, want > 0", hash, j.TokensUsed)
+	}
+	if !withTokens && j.TokensUsed != 0 {
+		t.Errorf("%s: tokens_used = %d, want 0", hash, j.TokensUsed)
+	}
+}
+
+func TestTranslationRunSingle(t *testing.T) {
+	setupTestDB(t)
+	run := runTranslation(t, TranslateOptions{Locale: testLocale}, &scriptedProvider{echo: "SET_ENTITY_HEALTH"})
+
+	status := run.Status()
+	if status.State != RunFinished || status.Total != 4 || status.Processed != 4 || status.Review != 1 || status.Failed != 0 {
+		t.Fatalf("run status = %+v", status)
+	}
+
+	assertTranslated(t, "0x0000000000000001", "[fr] Returns the ped handle of the given player.",
+		map[string]string{"playerId": "[fr] The player index to look up."})
+	assertTranslated(t, "0x0000000000000004", "[fr] Gets the world position of an entity.",
+		map[string]string{"entity": "[fr] The entity to query."})
+
+	jobs := jobsByHash(t)
+	assertJob(t, jobs, "0x0000000000000001", core.JobDone, 1, true)
+	assertJob(t, jobs, "0x0000000000000002", core.JobDone, 0, false)
+	assertJob(t, jobs, "0x0000000000000004", core.JobDone, 1, true)
+
+	// 原样返回原文未通过质检，重试 3 次后提交审核而不是写入译文
+	assertJob(t, jobs, "0x0000000000000003", core.JobReview, 3, true)
+	if tr, _ := core.GetTranslation(core.DefaultGame, "0x0000000000000003", testLocale); tr.Status != core.TranslationStatusNone || tr.Description != "" {
+		t.Errorf("QA-failed translation was stored: %+v", tr)
+	}
+	proposals, err := core.ListProposals(core.ProposalPending, core.DefaultGame, "0x0000000000000003")
+	if err != nil {
+		t.Fatalf("ListProposals: %v", err)
+	}
+	if len(proposals) != 1 || proposals[0].Field != core.DescriptionField(testLocale) || proposals[0].Author != AITranslatorName {
+		t.Fatalf("review proposals = %+v", proposals)
+	}
+}
+
+func TestTranslationRunBatchFallback(t *testing.T) {
+	setupTestDB(t)
+	opts := TranslateOptions{Locale: testLocale, BatchTokens: 4000, NoMemory: true}
+	run := runTranslation(t, opts, &scriptedProvider{drop: "GET_ENTITY_COORDS"})
+
+	status := run.Status()
+	if status.State != RunFinished || status.Processed != 4 || status.Failed != 0 || status.Review != 0 {
+		t.Fatalf("run status = %+v", status)
+	}
+	// 一次批量请求，加上被丢弃函数的单独重试
+	if status.Requests != 2 {
+		t.Errorf("requests = %d, want 2", status.Requests)
+	}
+
+	assertTranslated(t, "0x0000000000000001", "[fr] Returns the ped handle of the given player.", nil)
+	assertTranslated(t, "0x0000000000000003", "[fr] Sets the health of the entity to the given value.", nil)
+	assertTranslated(t, "0x0000000000000004", "[fr] Gets the world position of an entity.",
+		map[string]string{"entity": "[fr] The entity to query."})
+
+	jobs := jobsByHash(t)
+	assertJob(t, jobs, "0x0000000000000001", core.JobDone, 1, true)
+	assertJob(t, jobs, "0x0000000000000002", core.JobDone, 0, false)
+	assertJob(t, jobs, "0x0000000000000003", core.JobDone, 1, true)
+	assertJob(t, jobs, "0x0000000000000004", core.JobDone, 1, true)
+}
+
+func TestPlanMergeDiscardsPreviousOutput(t *testing.T) {
+	plan := &translationPlan{
+		Source:     "Gets the entity.",
+		Segments:   []string{"Gets the entity."},
+		Translated: map[string]string{},
+		Params:     map[string]string{},
+		Input: translator.Input{
+			Segments: map[string]string{"0": "Gets the entity."},
+			Params:   map[string]string{"entity": "The entity."},
+		},
+	}
+
+	// 批量结果只返回整段描述与多余参数，回退为单个请求后以新的结果为准
+	if err := plan.merge(&translator.Output{Description: "batch", Params: map[string]string{"entity": "batch entity", "ped": "extra"}}); err != nil {
+		t.Fatalf("merge batch output: %v", err)
+	}
+	if err := plan.merge(&translator.Output{Segments: map[string]string{"0": "single"}}); err != nil {
+		t.Fatalf("merge single output: %v", err)
+	}
+	if desc := plan.description(); desc != "single" {
+		t.Errorf("description = %q, want %q", desc, "single")
+	}
+	if _, ok := plan.Params["entity"]; ok || len(plan.Extra) != 0 {
+		t.Errorf("stale params kept: params = %v, extra = %v", plan.Params, plan.Extra)
+	}
+}
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"nativedb/internal/core"
+)
+
+// ErrSyncRunning 表示已有同步在进行中
+var ErrSyncRunning = errors.New("a sync is already in progress")
+
+var syncMu sync.Mutex
+
+/**
+ * @brief 初始化同步命令
+ */
+func init() {
+	Register("sync", "Check upstream native data for changes and import them.", handleSync)
+}
+
+/**
+ * @brief 处理同步命令
+ * @param args 命令参数
+ * @return error 同步错误
+ */
+func handleSync(args []string) error {
+	if core.Config == nil {
+		return fmt.Errorf("config not loaded")
+	}
+	if core.DB == nil {
+		core.InitDB(core.Config)
+	}
+	run, err := SyncNatives("cli")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Sync #%d finished: %s\n", run.ID, run.Status)
+	return nil
+}
+
+/**
+ * @brief 判断是否有同步正在进行
+ * @return bool 是否正在同步
+ */
+func SyncInProgress() bool {
+	if syncMu.TryLock() {
+		syncMu.Unlock()
+		return false
+	}
+	return true
+}
+
+/**
+ * @brief 检查配置了 URL 的上游数据源，有变化时重新导入并记录本次同步
+ * @param triggeredBy 触发方式 (schedule、manual 或 cli)
+ * @return *core.SyncRun 同步记录
+ * @return error 同步进行中或离线模式时返回错误，导入失败记录在同步记录中
+ */
+func SyncNatives(triggeredBy string) (*core.SyncRun, error) {
+	if core.Config.DataOffline {
+		return nil, fmt.Errorf("sync is disabled in offline mode")
+	}
+	if !syncMu.TryLock() {
+		return nil, ErrSyncRunning
+	}
+	defer syncMu.Unlock()
+
+	run := &core.SyncRun{TriggeredBy: triggeredBy, Status: core.SyncRunning, StartedAt: time.Now(), Files: []core.SyncFile{}, ImportRuns: []int64{}}
+	defer func() {
+		now := time.Now()
+		run.FinishedAt = &now
+		if err := core.SaveSyncRun(run); err != nil {
+			log.Printf("[Sync] Failed to save sync run: %v", err)
+		}
+	}()
+
+	manifest, err := core.LoadDataManifest(core.Config.DataManifest)
+	if err != nil {
+		run.Status, run.Error = core.SyncFailed, err.Error()
+		return run, nil
+	}
+
+	// 只检查配置了 URL 的数据源，本地数据源由用户自行维护
+	updated := make(map[string]bool)
+	for _, src := range core.Config.DataSources {
+		if src.URL == "" {
+			continue
+		}
+		file := core.SyncFile{Name: src.Path}
+		ok, err := fetchDataFile(src.Path, src.URL, false, manifest)
+		if err != nil {
+			file.Error = err.Error()
+		}
+		file.Updated = ok
+		updated[src.Name] = ok
+		run.Files = append(run.Files, file)
+	}
+
+	run.Status = core.SyncUnchanged
+	for _, src := range core.Config.SourcesOf(core.SourcePrimary, "") {
+		// 补丁数据会合并进同一游戏的主数据源，补丁变化时这些数据源都需要重新导入
+		changed := updated[src.Name]
+		for _, patch := range core.Config.SourcesOf(core.SourcePatch, src.Game) {
+			changed = changed || updated[patch.Name]
+		}
+		if !changed || !core.FileExists(src.Path) {
+			continue
+		}
+		report, err := runImportNative(src, src.Path, importOptions{Fetched: true})
+		if err != nil {
+			run.Status = core.SyncFailed
+			run.Error = fmt.Sprintf("%s: %v", src.Name, err)
+			return run, nil
+		}
+		run.Status = core.SyncImported
+		run.ImportRuns = append(run.ImportRuns, report.ID)
+		run.Written = append(run.Written, report.Written...)
+	}
+	return run, nil
+}
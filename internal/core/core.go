@@ -7,10 +7,8 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"math/rand"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -53,21 +51,53 @@ type AppConfig struct {
 	RedisDB   int    `json:"redis_db"`
 
 	// AI Config
-	AiBaseUrl string `json:"ai_base_url"`
-	AiApiKey  string `json:"ai_api_key"`
-	AiModel   string `json:"ai_model"`
-	AiWorkers int    `json:"ai_workers"`
+	AiProvider string `json:"ai_provider"`
+	AiBaseUrl  string `json:"ai_base_url"`
+	AiApiKey   string `json:"ai_api_key"`
+	AiModel    string `json:"ai_model"`
+	AiWorkers  int    `json:"ai_workers"`
+
+	// AI 翻译目标语言、提示词模板文件，以及首次启动时写入术语表的按语言划分的术语
+	AiTargetLocale string                       `json:"ai_target_locale"`
+	AiPromptFile   string                       `json:"ai_prompt_file"`
+	AiGlossary     map[string]map[string]string `json:"ai_glossary"`
+
+	// 翻译记忆模糊匹配阈值 (0-1)，命中的相似译文会作为参考发送给 AI
+	AiMemoryFuzzy float64 `json:"ai_memory_fuzzy"`
+
+	// 批量翻译时单次请求的输入 token 预算，0 表示每次请求只翻译一个函数
+	AiBatchTokens int `json:"ai_batch_tokens"`
+
+	// AI 调用限流 (每分钟请求数 / 每分钟 token 数，0 表示不限制) 与每百万 token 单价
+	AiRateRpm         int     `json:"ai_rate_rpm"`
+	AiRateTpm         int     `json:"ai_rate_tpm"`
+	AiPricePrompt     float64 `json:"ai_price_prompt"`
+	AiPriceCompletion float64 `json:"ai_price_completion"`
+
+	// 数据文件：离线模式 (导入时从不访问网络)、SHA-256 校验清单文件与下载超时 (秒)
+	DataOffline         bool   `json:"data_offline"`
+	DataManifest        string `json:"data_manifest"`
+	DataDownloadTimeout int    `json:"data_download_timeout"`
+
+	// 服务端定时检查上游数据并自动导入的间隔 (分钟)，0 表示不启用
+	SyncInterval int `json:"sync_interval"`
+
+	// 函数数据源，为空时使用内置的 GTA5、CFX 与 alloc8or 补丁
+	DataSources []DataSource `json:"data_sources"`
 
 	// Mirror
 	GravatarMirror string `json:"gravatar_mirror"`
 }
 
 type User struct {
-	ID           int    `json:"id"`
-	Username     string `json:"username"`
-	PasswordHash string `json:"-"`
-	Email        string `json:"email"`
-	Avatar       string `json:"avatar"`
+	ID           int       `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"`
+	Email        string    `json:"email"`
+	Role         string    `json:"role"`
+	Disabled     bool      `json:"disabled"`
+	Avatar       string    `json:"avatar"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 type MemCacheItem struct {
@@ -111,10 +141,14 @@ func LoadConfig(path string) (*AppConfig, error) {
 			RedisPort:      6379,
 			RedisPass:      "",
 			RedisDB:        0,
+			AiProvider:     "openai",
 			AiBaseUrl:      "https://api.deepseek.com",
 			AiApiKey:       "your-api-key-here",
 			AiModel:        "deepseek-chat",
 			AiWorkers:      10,
+			AiTargetLocale: DefaultLocale,
+			DataManifest:   DefaultDataManifest,
+			DataSources:    DefaultDataSources(),
 			GravatarMirror: "https://www.gravatar.com/avatar/",
 		}
 
@@ -148,6 +182,23 @@ func LoadConfig(path string) (*AppConfig, error) {
 	if config.AiWorkers <= 0 {
 		config.AiWorkers = 5
 	}
+	if config.AiMemoryFuzzy <= 0 || config.AiMemoryFuzzy > 1 {
+		config.AiMemoryFuzzy = MemoryFuzzyThreshold
+	}
+	if config.AiTargetLocale == "" {
+		config.AiTargetLocale = DefaultLocale
+	}
+	if config.DataManifest == "" {
+		config.DataManifest = DefaultDataManifest
+	}
+	if config.DataDownloadTimeout <= 0 {
+		config.DataDownloadTimeout = 60
+	}
+	sources, err := normalizeDataSources(config.DataSources)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data_sources: %v", err)
+	}
+	config.DataSources = sources
 	if config.GravatarMirror == "" {
 		config.GravatarMirror = "https://www.gravatar.com/avatar/"
 	}
@@ -209,16 +260,17 @@ func InitDB(config *AppConfig) {
 }
 
 /**
- * @brief 创建数据库表
+ * @brief 获取建表语句
  * @param dbType 数据库类型 (mysql 或 sqlite)
+ * @return []string 建表与建索引语句
  */
-func createTables(dbType string) {
+func schemaStatements(dbType string) []string {
 	var tables []string
 
 	if dbType == "sqlite" {
 		tables = []string{
 			`CREATE TABLE IF NOT EXISTS natives (
-				hash TEXT PRIMARY KEY,
+				hash TEXT NOT NULL,
 				jhash TEXT,
 				name TEXT,
 				name_sp TEXT DEFAULT '', 
@@ -226,14 +278,16 @@ func createTables(dbType string) {
 				params TEXT,
 				return_type TEXT DEFAULT 'void',
 				apiset TEXT DEFAULT 'client',
-				game TEXT DEFAULT 'gta5',
+				game TEXT NOT NULL DEFAULT 'gta5',
 				build_number INTEGER DEFAULT 0,
 				description_original TEXT,
 				description_cn TEXT,
 				translation_status INTEGER DEFAULT 0,
 				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (game, hash)
 			);`,
+			`CREATE INDEX IF NOT EXISTS idx_hash ON natives(hash);`,
 			`CREATE INDEX IF NOT EXISTS idx_name ON natives(name);`,
 			`CREATE INDEX IF NOT EXISTS idx_namespace ON natives(namespace);`,
 			`CREATE INDEX IF NOT EXISTS idx_status ON natives(translation_status);`,
@@ -243,22 +297,26 @@ func createTables(dbType string) {
 				username TEXT NOT NULL UNIQUE,
 				password_hash TEXT NOT NULL,
 				email TEXT NOT NULL,
+				role TEXT DEFAULT 'viewer',
+				disabled INTEGER DEFAULT 0,
 				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 			);`,
 
 			`CREATE TABLE IF NOT EXISTS native_examples (
 				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				game TEXT NOT NULL DEFAULT 'gta5',
 				native_hash TEXT NOT NULL,
 				language TEXT DEFAULT 'lua',
 				code TEXT NOT NULL,
 				contributor TEXT DEFAULT 'System',
 				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-				FOREIGN KEY (native_hash) REFERENCES natives(hash) ON DELETE CASCADE
+				FOREIGN KEY (game, native_hash) REFERENCES natives(game, hash) ON DELETE CASCADE
 			);`,
 			`CREATE INDEX IF NOT EXISTS idx_ex_hash ON native_examples(native_hash);`,
 
 			`CREATE TABLE IF NOT EXISTS native_sources (
 				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				game TEXT NOT NULL DEFAULT 'gta5',
 				native_hash TEXT NOT NULL,
 				code_content TEXT NOT NULL,
 				code_lang TEXT DEFAULT 'cpp',
@@ -267,9 +325,129 @@ func createTables(dbType string) {
 				contributor TEXT DEFAULT 'System',
 				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-				FOREIGN KEY (native_hash) REFERENCES natives(hash) ON DELETE CASCADE
+				FOREIGN KEY (game, native_hash) REFERENCES natives(game, hash) ON DELETE CASCADE
 			);`,
 			`CREATE INDEX IF NOT EXISTS idx_src_hash ON native_sources(native_hash);`,
+
+			`CREATE TABLE IF NOT EXISTS native_translations (
+				game TEXT NOT NULL DEFAULT 'gta5',
+				native_hash TEXT NOT NULL,
+				locale TEXT NOT NULL,
+				description TEXT,
+				params TEXT,
+				status INTEGER DEFAULT 0,
+				source_hash TEXT,
+				outdated INTEGER DEFAULT 0,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (game, native_hash, locale),
+				FOREIGN KEY (game, native_hash) REFERENCES natives(game, hash) ON DELETE CASCADE
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_tr_locale ON native_translations(locale, status);`,
+
+			`CREATE TABLE IF NOT EXISTS native_translation_jobs (
+				game TEXT NOT NULL DEFAULT 'gta5',
+				native_hash TEXT NOT NULL,
+				locale TEXT NOT NULL,
+				status TEXT NOT NULL DEFAULT 'pending',
+				attempts INTEGER DEFAULT 0,
+				last_error TEXT,
+				tokens_used INTEGER DEFAULT 0,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (game, native_hash, locale),
+				FOREIGN KEY (game, native_hash) REFERENCES natives(game, hash) ON DELETE CASCADE
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_job_status ON native_translation_jobs(locale, status);`,
+
+			`CREATE TABLE IF NOT EXISTS native_glossary (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				term TEXT NOT NULL,
+				locale TEXT NOT NULL,
+				translation TEXT NOT NULL,
+				note TEXT DEFAULT '',
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				UNIQUE (term, locale)
+			);`,
+
+			`CREATE TABLE IF NOT EXISTS native_translation_memory (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				locale TEXT NOT NULL,
+				source_hash TEXT NOT NULL,
+				source_text TEXT NOT NULL,
+				target_text TEXT NOT NULL,
+				native_hash TEXT,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				UNIQUE (locale, source_hash)
+			);`,
+
+			`CREATE TABLE IF NOT EXISTS native_revisions (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				game TEXT NOT NULL DEFAULT 'gta5',
+				native_hash TEXT NOT NULL,
+				field TEXT NOT NULL,
+				old_value TEXT,
+				new_value TEXT,
+				author TEXT DEFAULT 'System',
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (game, native_hash) REFERENCES natives(game, hash) ON DELETE CASCADE
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_rev_hash ON native_revisions(native_hash);`,
+
+			`CREATE TABLE IF NOT EXISTS native_proposals (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				game TEXT NOT NULL DEFAULT 'gta5',
+				native_hash TEXT NOT NULL,
+				field TEXT NOT NULL,
+				base_value TEXT,
+				value TEXT NOT NULL,
+				author TEXT NOT NULL,
+				status TEXT NOT NULL DEFAULT 'pending',
+				reviewer TEXT DEFAULT NULL,
+				review_comment TEXT DEFAULT '',
+				note TEXT DEFAULT '',
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				reviewed_at DATETIME DEFAULT NULL,
+				FOREIGN KEY (game, native_hash) REFERENCES natives(game, hash) ON DELETE CASCADE
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_prop_status ON native_proposals(status);`,
+			`CREATE INDEX IF NOT EXISTS idx_prop_hash ON native_proposals(native_hash);`,
+
+			`CREATE TABLE IF NOT EXISTS native_import_runs (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				source TEXT NOT NULL,
+				game TEXT DEFAULT '',
+				started_at DATETIME,
+				finished_at DATETIME,
+				processed INTEGER DEFAULT 0,
+				added INTEGER DEFAULT 0,
+				removed INTEGER DEFAULT 0,
+				renamed INTEGER DEFAULT 0,
+				signature_changed INTEGER DEFAULT 0,
+				description_changed INTEGER DEFAULT 0,
+				report TEXT
+			);`,
+
+			`CREATE TABLE IF NOT EXISTS native_sync_runs (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				triggered_by TEXT NOT NULL,
+				status TEXT NOT NULL,
+				started_at DATETIME,
+				finished_at DATETIME,
+				files TEXT,
+				import_runs TEXT,
+				error TEXT DEFAULT ''
+			);`,
+
+			`CREATE VIRTUAL TABLE IF NOT EXISTS native_search USING fts5(
+				game UNINDEXED,
+				hash UNINDEXED,
+				name,
+				name_sp,
+				description_original,
+				description_cn,
+				params_text,
+				tokenize = 'unicode61'
+			);`,
 		}
 	} else {
 		// MySQL Schema
@@ -283,14 +461,15 @@ func createTables(dbType string) {
 				params longtext CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT NULL,
 				return_type varchar(100) DEFAULT 'void',
 				apiset varchar(20) DEFAULT 'client',
-				game varchar(20) DEFAULT 'gta5',
+				game varchar(20) NOT NULL DEFAULT 'gta5',
 				build_number int(11) DEFAULT 0,
 				description_original text DEFAULT NULL,
 				description_cn text DEFAULT NULL,
 				translation_status tinyint(1) DEFAULT 0,
 				created_at timestamp NULL DEFAULT current_timestamp(),
 				updated_at timestamp NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
-				PRIMARY KEY (hash),
+				PRIMARY KEY (game, hash),
+				KEY idx_hash (hash),
 				KEY idx_name (name),
 				KEY idx_namespace (namespace),
 				KEY idx_status (translation_status)
@@ -301,6 +480,8 @@ func createTables(dbType string) {
 				username varchar(50) NOT NULL,
 				password_hash varchar(255) NOT NULL,
 				email varchar(100) NOT NULL,
+				role varchar(20) DEFAULT 'viewer',
+				disabled tinyint(1) DEFAULT 0,
 				created_at timestamp NULL DEFAULT current_timestamp(),
 				PRIMARY KEY (id),
 				UNIQUE KEY username (username)
@@ -308,6 +489,7 @@ func createTables(dbType string) {
 
 			`CREATE TABLE IF NOT EXISTS native_examples (
 				id int(11) NOT NULL AUTO_INCREMENT,
+				game varchar(20) NOT NULL DEFAULT 'gta5',
 				native_hash char(18) NOT NULL,
 				language varchar(10) DEFAULT 'lua',
 				code text NOT NULL,
@@ -315,11 +497,13 @@ func createTables(dbType string) {
 				updated_at datetime DEFAULT current_timestamp(),
 				PRIMARY KEY (id),
 				KEY native_hash (native_hash),
-				CONSTRAINT native_examples_ibfk_1 FOREIGN KEY (native_hash) REFERENCES natives (hash) ON DELETE CASCADE
+				KEY idx_example_native (game, native_hash),
+				CONSTRAINT fk_example_native FOREIGN KEY (game, native_hash) REFERENCES natives (game, hash) ON DELETE CASCADE
 			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci ROW_FORMAT=DYNAMIC;`,
 
 			`CREATE TABLE IF NOT EXISTS native_sources (
 				id int(10) unsigned NOT NULL AUTO_INCREMENT,
+				game varchar(20) NOT NULL DEFAULT 'gta5',
 				native_hash char(18) NOT NULL,
 				code_content text NOT NULL,
 				code_lang varchar(20) DEFAULT 'cpp',
@@ -330,42 +514,297 @@ func createTables(dbType string) {
 				updated_at timestamp NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
 				PRIMARY KEY (id),
 				KEY idx_native_hash (native_hash),
-				CONSTRAINT fk_source_native FOREIGN KEY (native_hash) REFERENCES natives (hash) ON DELETE CASCADE
+				KEY idx_source_native (game, native_hash),
+				CONSTRAINT fk_source_native FOREIGN KEY (game, native_hash) REFERENCES natives (game, hash) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci ROW_FORMAT=DYNAMIC;`,
+
+			`CREATE TABLE IF NOT EXISTS native_translations (
+				game varchar(20) NOT NULL DEFAULT 'gta5',
+				native_hash char(18) NOT NULL,
+				locale varchar(16) NOT NULL,
+				description text DEFAULT NULL,
+				params longtext DEFAULT NULL,
+				status tinyint(1) DEFAULT 0,
+				source_hash char(64) DEFAULT NULL,
+				outdated tinyint(1) DEFAULT 0,
+				updated_at timestamp NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+				PRIMARY KEY (game, native_hash, locale),
+				KEY idx_tr_locale (locale, status),
+				CONSTRAINT fk_translation_native FOREIGN KEY (game, native_hash) REFERENCES natives (game, hash) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci ROW_FORMAT=DYNAMIC;`,
+
+			`CREATE TABLE IF NOT EXISTS native_translation_jobs (
+				game varchar(20) NOT NULL DEFAULT 'gta5',
+				native_hash char(18) NOT NULL,
+				locale varchar(16) NOT NULL,
+				status enum('pending','running','done','failed','review') NOT NULL DEFAULT 'pending',
+				attempts int(11) DEFAULT 0,
+				last_error text DEFAULT NULL,
+				tokens_used int(11) DEFAULT 0,
+				created_at timestamp NULL DEFAULT current_timestamp(),
+				updated_at timestamp NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+				PRIMARY KEY (game, native_hash, locale),
+				KEY idx_job_status (locale, status),
+				CONSTRAINT fk_job_native FOREIGN KEY (game, native_hash) REFERENCES natives (game, hash) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci ROW_FORMAT=DYNAMIC;`,
+
+			`CREATE TABLE IF NOT EXISTS native_glossary (
+				id int(11) NOT NULL AUTO_INCREMENT,
+				term varchar(100) NOT NULL,
+				locale varchar(16) NOT NULL,
+				translation varchar(255) NOT NULL,
+				note varchar(255) DEFAULT '',
+				updated_at timestamp NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+				PRIMARY KEY (id),
+				UNIQUE KEY uk_glossary_term (term, locale)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci ROW_FORMAT=DYNAMIC;`,
+
+			`CREATE TABLE IF NOT EXISTS native_translation_memory (
+				id int(11) NOT NULL AUTO_INCREMENT,
+				locale varchar(16) NOT NULL,
+				source_hash char(64) NOT NULL,
+				source_text text NOT NULL,
+				target_text text NOT NULL,
+				native_hash char(18) DEFAULT NULL,
+				updated_at timestamp NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+				PRIMARY KEY (id),
+				UNIQUE KEY uk_memory_source (locale, source_hash)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci ROW_FORMAT=DYNAMIC;`,
+
+			`CREATE TABLE IF NOT EXISTS native_revisions (
+				id int(11) NOT NULL AUTO_INCREMENT,
+				game varchar(20) NOT NULL DEFAULT 'gta5',
+				native_hash char(18) NOT NULL,
+				field varchar(50) NOT NULL,
+				old_value longtext DEFAULT NULL,
+				new_value longtext DEFAULT NULL,
+				author varchar(50) DEFAULT 'System',
+				created_at timestamp NULL DEFAULT current_timestamp(),
+				PRIMARY KEY (id),
+				KEY idx_rev_hash (native_hash),
+				KEY idx_rev_native (game, native_hash),
+				CONSTRAINT fk_revision_native FOREIGN KEY (game, native_hash) REFERENCES natives (game, hash) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci ROW_FORMAT=DYNAMIC;`,
+
+			`CREATE TABLE IF NOT EXISTS native_proposals (
+				id int(11) NOT NULL AUTO_INCREMENT,
+				game varchar(20) NOT NULL DEFAULT 'gta5',
+				native_hash char(18) NOT NULL,
+				field varchar(50) NOT NULL,
+				base_value longtext DEFAULT NULL,
+				value longtext NOT NULL,
+				author varchar(50) NOT NULL,
+				status enum('pending','approved','rejected') NOT NULL DEFAULT 'pending',
+				reviewer varchar(50) DEFAULT NULL,
+				review_comment text DEFAULT NULL,
+				note text DEFAULT NULL,
+				created_at timestamp NULL DEFAULT current_timestamp(),
+				reviewed_at timestamp NULL DEFAULT NULL,
+				PRIMARY KEY (id),
+				KEY idx_prop_status (status),
+				KEY idx_prop_hash (native_hash),
+				KEY idx_prop_native (game, native_hash),
+				CONSTRAINT fk_proposal_native FOREIGN KEY (game, native_hash) REFERENCES natives (game, hash) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci ROW_FORMAT=DYNAMIC;`,
+
+			`CREATE TABLE IF NOT EXISTS native_import_runs (
+				id int(11) NOT NULL AUTO_INCREMENT,
+				source varchar(255) NOT NULL,
+				game varchar(20) DEFAULT '',
+				started_at timestamp NULL DEFAULT NULL,
+				finished_at timestamp NULL DEFAULT NULL,
+				processed int(11) DEFAULT 0,
+				added int(11) DEFAULT 0,
+				removed int(11) DEFAULT 0,
+				renamed int(11) DEFAULT 0,
+				signature_changed int(11) DEFAULT 0,
+				description_changed int(11) DEFAULT 0,
+				report longtext DEFAULT NULL,
+				PRIMARY KEY (id)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci ROW_FORMAT=DYNAMIC;`,
+
+			`CREATE TABLE IF NOT EXISTS native_sync_runs (
+				id int(11) NOT NULL AUTO_INCREMENT,
+				triggered_by varchar(20) NOT NULL,
+				status varchar(20) NOT NULL,
+				started_at timestamp NULL DEFAULT NULL,
+				finished_at timestamp NULL DEFAULT NULL,
+				files text DEFAULT NULL,
+				import_runs text DEFAULT NULL,
+				error text DEFAULT NULL,
+				PRIMARY KEY (id)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci ROW_FORMAT=DYNAMIC;`,
+
+			`CREATE TABLE IF NOT EXISTS native_search (
+				game varchar(20) NOT NULL DEFAULT 'gta5',
+				hash char(18) NOT NULL,
+				name varchar(100) DEFAULT NULL,
+				name_sp varchar(100) DEFAULT NULL,
+				description_original text DEFAULT NULL,
+				description_cn text DEFAULT NULL,
+				params_text text DEFAULT NULL,
+				PRIMARY KEY (game, hash),
+				FULLTEXT KEY ft_native_search (name, name_sp, description_original, description_cn, params_text)
 			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci ROW_FORMAT=DYNAMIC;`,
 		}
 	}
+	return tables
+}
 
-	for _, sqlStmt := range tables {
+/**
+ * @brief 创建数据库表
+ * @param dbType 数据库类型 (mysql 或 sqlite)
+ */
+func createTables(dbType string) {
+	// 旧版 MySQL 数据库的 natives 以 hash 为主键，需先迁移为 (game, hash)，引用它的新表才能建立外键
+	if dbType != "sqlite" {
+		migrateMySQLNativeKey()
+	}
+	for _, sqlStmt := range schemaStatements(dbType) {
 		if _, err := DB.Exec(sqlStmt); err != nil {
 			log.Printf("Warning: Failed to ensure table exists. Error: %v", err)
 		}
 	}
 
 	autoMigrate(dbType)
+	migrateTranslations()
+	backfillSourceHashes()
+	seedGlossary()
+	ensureSearchIndex()
 }
 
 func autoMigrate(dbType string) {
 	fmt.Println("Checking database schema...")
 
 	if dbType == "sqlite" {
-		_, err := DB.Exec("ALTER TABLE natives ADD COLUMN name_sp TEXT DEFAULT '';")
-		if err == nil {
-			fmt.Println("Migrated: Added 'name_sp' column to 'natives' table (SQLite).")
+		ensureColumn("natives", "name_sp", "TEXT DEFAULT ''")
+		if ensureColumn("native_users", "role", "TEXT DEFAULT 'viewer'") {
+			promoteExistingUsers()
 		}
+		ensureColumn("native_users", "disabled", "INTEGER DEFAULT 0")
+		ensureColumn("native_translations", "source_hash", "TEXT")
+		ensureColumn("native_translations", "outdated", "INTEGER DEFAULT 0")
+		ensureColumn("native_proposals", "note", "TEXT DEFAULT ''")
 	} else {
-		// MySQL 检查列是否存在
-		var dummy string
-		err := DB.QueryRow("SELECT name_sp FROM natives LIMIT 1").Scan(&dummy)
-		if err != nil {
-			// 列不存在，添加
-			_, err := DB.Exec("ALTER TABLE natives ADD COLUMN name_sp varchar(100) DEFAULT '' AFTER name;")
-			if err != nil {
+		ensureColumn("natives", "name_sp", "varchar(100) DEFAULT '' AFTER name")
+		if ensureColumn("native_users", "role", "varchar(20) DEFAULT 'viewer' AFTER email") {
+			promoteExistingUsers()
+		}
+		ensureColumn("native_users", "disabled", "tinyint(1) DEFAULT 0 AFTER role")
+		ensureColumn("native_translations", "source_hash", "char(64) DEFAULT NULL AFTER status")
+		ensureColumn("native_translations", "outdated", "tinyint(1) DEFAULT 0 AFTER source_hash")
+		ensureColumn("native_proposals", "note", "text DEFAULT NULL AFTER review_comment")
+
+		// 旧版本的任务状态枚举缺少 review
+		var columnType string
+		err := DB.QueryRow("SELECT COLUMN_TYPE FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'native_translation_jobs' AND COLUMN_NAME = 'status'").Scan(&columnType)
+		if err == nil && !strings.Contains(columnType, "'review'") {
+			if _, err := DB.Exec("ALTER TABLE native_translation_jobs MODIFY status enum('pending','running','done','failed','review') NOT NULL DEFAULT 'pending'"); err != nil {
 				log.Printf("Migration failed: %v", err)
 			} else {
-				fmt.Println("Migrated: Added 'name_sp' column to 'natives' table (MySQL).")
+				fmt.Println("Migrated: Added 'review' status to 'native_translation_jobs' table.")
 			}
 		}
 	}
+
+	fixUserRoleDefault(dbType)
+	migrateNativeIdentity(dbType)
+}
+
+/**
+ * @brief 角色功能之前的用户都是管理员，新增 role 列后保持其权限不变
+ */
+func promoteExistingUsers() {
+	if _, err := DB.Exec("UPDATE native_users SET role = ?", RoleAdmin); err != nil {
+		log.Printf("Migration failed: %v", err)
+		return
+	}
+	fmt.Println("Migrated: Existing users keep the 'admin' role.")
+}
+
+/**
+ * @brief 旧版本迁移时 role 列的默认值为 admin，未指定角色的插入会创建管理员，将默认值改为 viewer
+ * @param dbType 数据库类型 (mysql 或 sqlite)
+ */
+func fixUserRoleDefault(dbType string) {
+	var dflt sql.NullString
+	if dbType == "sqlite" {
+		DB.QueryRow("SELECT dflt_value FROM pragma_table_info('native_users') WHERE name = 'role'").Scan(&dflt)
+	} else {
+		DB.QueryRow("SELECT COLUMN_DEFAULT FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'native_users' AND COLUMN_NAME = 'role'").Scan(&dflt)
+	}
+	if strings.Trim(dflt.String, "'") != RoleAdmin {
+		return
+	}
+
+	var err error
+	if dbType == "sqlite" {
+		// SQLite 不支持修改列默认值，按当前结构重建表
+		var tx *sql.Tx
+		if tx, err = DB.Begin(); err == nil {
+			if err = rebuildSQLiteTable(tx, "native_users", nil); err == nil {
+				err = tx.Commit()
+			} else {
+				tx.Rollback()
+			}
+		}
+	} else {
+		_, err = DB.Exec("ALTER TABLE native_users ALTER role SET DEFAULT 'viewer'")
+	}
+	if err != nil {
+		log.Printf("Migration failed: %v", err)
+		return
+	}
+	fmt.Println("Migrated: 'native_users.role' now defaults to 'viewer'.")
+}
+
+/**
+ * @brief 检查列是否存在
+ * @param table 表名
+ * @param column 列名
+ * @return bool 是否存在
+ */
+func columnExists(table, column string) bool {
+	rows, err := DB.Query(fmt.Sprintf("SELECT %s FROM %s LIMIT 0", column, table))
+	if err != nil {
+		return false
+	}
+	rows.Close()
+	return true
+}
+
+/**
+ * @brief 检查表是否存在
+ * @param table 表名
+ * @return bool 是否存在
+ */
+func tableExists(table string) bool {
+	rows, err := DB.Query(fmt.Sprintf("SELECT 1 FROM %s LIMIT 0", table))
+	if err != nil {
+		return false
+	}
+	rows.Close()
+	return true
+}
+
+/**
+ * @brief 检查列是否存在，不存在则添加
+ * @param table 表名
+ * @param column 列名
+ * @param definition 列定义
+ * @return bool 是否新增了该列
+ */
+func ensureColumn(table, column, definition string) bool {
+	if columnExists(table, column) {
+		return false
+	}
+
+	if _, err := DB.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, column, definition)); err != nil {
+		log.Printf("Migration failed: %v", err)
+		return false
+	}
+	fmt.Printf("Migrated: Added '%s' column to '%s' table.\n", column, table)
+	return true
 }
 
 /**
@@ -447,33 +886,6 @@ func Joaat(key string) string {
 	return fmt.Sprintf("0x%08X", hash)
 }
 
-/**
- * @brief 下载文件
- * @param filepath 目标文件路径
- * @param url 源文件 URL
- * @return error 下载错误
- */
-func DownloadFile(filepath string, url string) error {
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("bad status: %s", resp.Status)
-	}
-
-	out, err := os.Create(filepath)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-	_, err = io.Copy(out, resp.Body)
-	return err
-}
-
 /**
  * @brief 检查文件是否存在
  * @param filename 文件路径
@@ -0,0 +1,229 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DefaultDataManifest 为默认的数据文件校验清单
+const DefaultDataManifest = "data_manifest.json"
+
+// DataManifest 为数据文件的期望 SHA-256 校验值，键为文件名 (不含目录)
+type DataManifest map[string]string
+
+// downloadMeta 为已下载文件的缓存校验信息，保存在 <文件>.meta 中用于条件请求
+type downloadMeta struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	SHA256       string `json:"sha256"`
+}
+
+/**
+ * @brief 加载数据文件校验清单
+ * @param path 清单路径
+ * @return DataManifest 校验清单，文件不存在时为空清单
+ * @return error 读取或解析错误
+ */
+func LoadDataManifest(path string) (DataManifest, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return DataManifest{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := DataManifest{}
+	if err := json.Unmarshal(content, &m); err != nil {
+		return nil, fmt.Errorf("invalid data manifest %s: %v", path, err)
+	}
+	return m, nil
+}
+
+/**
+ * @brief 保存数据文件校验清单
+ * @param path 清单路径
+ * @param m 校验清单
+ * @return error 写入错误
+ */
+func SaveDataManifest(path string, m DataManifest) error {
+	content, err := json.MarshalIndent(m, "", "    ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, content)
+}
+
+/**
+ * @brief 获取文件的期望校验值
+ * @param path 文件路径
+ * @return string SHA-256，未固定时为空字符串
+ */
+func (m DataManifest) Expected(path string) string {
+	return m[filepath.Base(path)]
+}
+
+/**
+ * @brief 校验文件是否与清单中固定的 SHA-256 一致，清单中没有的文件不校验
+ * @param path 文件路径
+ * @return error 校验失败或读取错误
+ */
+func (m DataManifest) Verify(path string) error {
+	expected := m.Expected(path)
+	if expected == "" {
+		return nil
+	}
+	actual, err := FileSHA256(path)
+	if err != nil {
+		return err
+	}
+	if actual != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", path, expected, actual)
+	}
+	return nil
+}
+
+/**
+ * @brief 列出清单中的文件名
+ * @return []string 按名称排序的文件名
+ */
+func (m DataManifest) Files() []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/**
+ * @brief 计算文件的 SHA-256
+ * @param path 文件路径
+ * @return string 十六进制 SHA-256
+ * @return error 读取错误
+ */
+func FileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+/**
+ * @brief 判断文件是否由 DownloadFile 下载 (存在缓存校验信息)，这类文件通过条件请求刷新
+ * @param path 文件路径
+ * @return bool 是否由下载得到
+ */
+func IsDownloaded(path string) bool {
+	return FileExists(path + ".meta")
+}
+
+/**
+ * @brief 下载文件：带超时，先写入临时文件并校验后再替换，已下载过的文件使用 ETag/If-Modified-Since 条件请求
+ * @param path 目标文件路径
+ * @param url 源文件 URL
+ * @param expectedSHA256 期望的 SHA-256，为空时不校验
+ * @return bool 文件是否有更新 (服务器返回 304 时为 false)
+ * @return error 下载或校验错误，出错时原文件保持不变
+ */
+func DownloadFile(path, url, expectedSHA256 string) (bool, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+
+	var meta downloadMeta
+	if content, err := os.ReadFile(path + ".meta"); err == nil && json.Unmarshal(content, &meta) == nil && meta.URL == url && FileExists(path) {
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+
+	timeout := 60
+	if Config != nil && Config.DataDownloadTimeout > 0 {
+		timeout = Config.DataDownloadTimeout
+	}
+	client := &http.Client{Timeout: time.Duration(timeout) * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("bad status: %s", resp.Status)
+	}
+
+	// 临时文件与目标文件位于同一目录，保证重命名是原子操作
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, h), resp.Body); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+	os.Chmod(tmp.Name(), 0644)
+	sum := hex.EncodeToString(h.Sum(nil))
+	if expectedSHA256 != "" && sum != expectedSHA256 {
+		return false, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", url, expectedSHA256, sum)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return false, err
+	}
+
+	meta = downloadMeta{URL: url, ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified"), SHA256: sum}
+	if content, err := json.MarshalIndent(meta, "", "    "); err == nil {
+		writeFileAtomic(path+".meta", content)
+	}
+	return true, nil
+}
+
+/**
+ * @brief 先写入临时文件再重命名，避免中断时留下不完整的文件
+ * @param path 目标文件路径
+ * @param content 文件内容
+ * @return error 写入错误
+ */
+func writeFileAtomic(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	os.Chmod(tmp.Name(), 0644)
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	// SourcePrimary 为主数据源，导入时写入函数
+	SourcePrimary = "primary"
+	// SourcePatch 为补丁数据源，只用于补全同一游戏主数据源中的名称、描述与示例
+	SourcePatch = "patch"
+
+	// SourceFormatJSON 为 命名空间 -> 哈希 -> 函数 的 JSON 格式 (static.cfx.re 与 alloc8or 使用)
+	SourceFormatJSON = "json"
+)
+
+// DataSource 为一个函数数据源，Path 为本地文件，配置了 URL 时缺少的文件会自动下载
+type DataSource struct {
+	Name     string `json:"name"`
+	URL      string `json:"url,omitempty"`
+	Path     string `json:"path"`
+	Format   string `json:"format"`
+	Game     string `json:"game"`
+	Apiset   string `json:"apiset"`
+	Priority int    `json:"priority"`
+	Type     string `json:"type"`
+}
+
+/**
+ * @brief 获取内置的默认数据源 (GTA5、CFX 与 alloc8or 补丁)，其他游戏需在配置中添加数据源
+ * @return []DataSource 默认数据源
+ */
+func DefaultDataSources() []DataSource {
+	return []DataSource{
+		{Name: "gta5", URL: "https://static.cfx.re/natives/natives.json", Path: "natives.json", Format: SourceFormatJSON, Game: "gta5", Apiset: "client", Priority: 0, Type: SourcePrimary},
+		{Name: "cfx", URL: "https://static.cfx.re/natives/natives_cfx.json", Path: "natives_cfx.json", Format: SourceFormatJSON, Game: "gta5", Apiset: "client", Priority: 10, Type: SourcePrimary},
+		{Name: "alloc8or", URL: "https://github.com/alloc8or/gta5-nativedb-data/raw/master/natives.json", Path: "natives_github.json", Format: SourceFormatJSON, Game: "gta5", Apiset: "client", Priority: 0, Type: SourcePatch},
+	}
+}
+
+/**
+ * @brief 补全数据源的默认值并校验配置
+ * @param sources 配置中的数据源，为空时使用内置的默认数据源
+ * @return []DataSource 按优先级升序排列的数据源 (优先级相同时保持配置顺序)
+ * @return error 配置错误
+ */
+func normalizeDataSources(sources []DataSource) ([]DataSource, error) {
+	if len(sources) == 0 {
+		return DefaultDataSources(), nil
+	}
+
+	result := make([]DataSource, 0, len(sources))
+	names := make(map[string]bool, len(sources))
+	paths := make(map[string]string, len(sources))
+	for i, src := range sources {
+		src.Name = strings.TrimSpace(src.Name)
+		if src.Name == "" {
+			return nil, fmt.Errorf("data source #%d has no name", i+1)
+		}
+		if names[src.Name] {
+			return nil, fmt.Errorf("duplicate data source name: %s", src.Name)
+		}
+		names[src.Name] = true
+
+		if src.Path == "" {
+			if src.URL == "" {
+				return nil, fmt.Errorf("data source %s needs a url or a path", src.Name)
+			}
+			src.Path = src.Name + ".json"
+		}
+		// 校验清单以文件名为键，同名文件会互相覆盖
+		base := path.Base(strings.ReplaceAll(src.Path, "\\", "/"))
+		if other, ok := paths[base]; ok {
+			return nil, fmt.Errorf("data sources %s and %s use the same file name %s", other, src.Name, base)
+		}
+		paths[base] = src.Name
+
+		if src.Format == "" {
+			src.Format = SourceFormatJSON
+		}
+		if src.Format != SourceFormatJSON {
+			return nil, fmt.Errorf("data source %s has unsupported format %q", src.Name, src.Format)
+		}
+		if src.Type == "" {
+			src.Type = SourcePrimary
+		}
+		if src.Type != SourcePrimary && src.Type != SourcePatch {
+			return nil, fmt.Errorf("data source %s has invalid type %q (expected primary or patch)", src.Name, src.Type)
+		}
+		src.Game = strings.ToLower(src.Game)
+		if src.Game == "" {
+			src.Game = DefaultGame
+		}
+		if src.Apiset == "" {
+			src.Apiset = "client"
+		}
+		result = append(result, src)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Priority < result[j].Priority })
+	return result, nil
+}
+
+/**
+ * @brief 按名称查找数据源
+ * @param name 数据源名称
+ * @return DataSource 数据源
+ * @return bool 是否存在
+ */
+func (c *AppConfig) FindDataSource(name string) (DataSource, bool) {
+	for _, src := range c.DataSources {
+		if src.Name == name {
+			return src, true
+		}
+	}
+	return DataSource{}, false
+}
+
+/**
+ * @brief 获取指定类型的数据源
+ * @param kind 类型 (primary 或 patch)
+ * @param game 游戏，为空时不限
+ * @return []DataSource 按优先级升序排列的数据源，同一函数以优先级高的数据源为准
+ */
+func (c *AppConfig) SourcesOf(kind, game string) []DataSource {
+	var result []DataSource
+	for _, src := range c.DataSources {
+		if src.Type == kind && (game == "" || src.Game == game) {
+			result = append(result, src)
+		}
+	}
+	return result
+}
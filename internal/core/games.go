@@ -0,0 +1,98 @@
+package core
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultGame 为未标明游戏的函数所属的游戏
+const DefaultGame = "gta5"
+
+// gameNames 为已知游戏的显示名称，键与 CFX 文档中的 game 字段一致
+var gameNames = map[string]string{
+	"gta5": "Grand Theft Auto V",
+	"rdr3": "Red Dead Redemption 2",
+	"ny":   "Grand Theft Auto IV",
+}
+
+// Game 为一个游戏及其函数数量
+type Game struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Natives int    `json:"natives"`
+}
+
+/**
+ * @brief 规范化游戏代码，已知游戏与数据源中配置的游戏均有效
+ * @param game 游戏代码 (不区分大小写)
+ * @return string 规范化后的游戏代码
+ * @return bool 是否有效
+ */
+func NormalizeGame(game string) (string, bool) {
+	game = strings.ToLower(strings.TrimSpace(game))
+	if game == "" {
+		return "", false
+	}
+	if _, ok := gameNames[game]; ok {
+		return game, true
+	}
+	if Config != nil {
+		for _, src := range Config.DataSources {
+			if src.Game == game {
+				return game, true
+			}
+		}
+	}
+	return "", false
+}
+
+/**
+ * @brief 获取游戏的显示名称
+ * @param game 游戏代码
+ * @return string 显示名称，未知游戏返回游戏代码本身
+ */
+func GameName(game string) string {
+	if name, ok := gameNames[game]; ok {
+		return name
+	}
+	return game
+}
+
+/**
+ * @brief 列出已导入函数或已配置数据源的游戏
+ * @return []Game 按游戏代码排序的游戏列表
+ * @return error 查询错误
+ */
+func ListGames() ([]Game, error) {
+	rows, err := DB.Query("SELECT game, COUNT(*) FROM natives GROUP BY game")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var game string
+		var n int
+		if err := rows.Scan(&game, &n); err != nil {
+			return nil, err
+		}
+		counts[game] += n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// 已配置但尚未导入的游戏也列出，函数数量为 0
+	for _, src := range Config.DataSources {
+		if _, ok := counts[src.Game]; !ok {
+			counts[src.Game] = 0
+		}
+	}
+
+	games := make([]Game, 0, len(counts))
+	for id, n := range counts {
+		games = append(games, Game{ID: id, Name: GameName(id), Natives: n})
+	}
+	sort.Slice(games, func(i, j int) bool { return games[i].ID < games[j].ID })
+	return games, nil
+}
@@ -0,0 +1,397 @@
+package core
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"nativedb/internal/models"
+)
+
+type GlossaryEntry struct {
+	ID          int        `json:"id,omitempty"`
+	Term        string     `json:"term"`
+	Locale      string     `json:"locale"`
+	Translation string     `json:"translation"`
+	Note        string     `json:"note"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+type GlossaryViolation struct {
+	Game       string `json:"game"`
+	NativeHash string `json:"native_hash"`
+	Name       string `json:"name"`
+	Locale     string `json:"locale"`
+	Term       string `json:"term"`
+	Expected   string `json:"expected"`
+}
+
+// defaultGlossaries 为首次启动时写入术语表的内置术语，术语与译文均可用 "/" 分隔多个写法
+var defaultGlossaries = map[string]map[string]string{
+	"zh-CN": {
+		"Ped":                "角色/实体",
+		"Vehicle":            "载具",
+		"Hash":               "哈希",
+		"Coordinates/Coords": "坐标",
+		"Player":             "玩家",
+		"Native":             "函数",
+		"true/false":         "true/false",
+	},
+	"zh-TW": {
+		"Ped":                "角色/實體",
+		"Vehicle":            "載具",
+		"Hash":               "雜湊",
+		"Coordinates/Coords": "座標",
+		"Player":             "玩家",
+		"Native":             "函式",
+		"true/false":         "true/false",
+	},
+	"ja": {
+		"Ped":                "Ped（キャラクター）",
+		"Vehicle":            "車両",
+		"Hash":               "ハッシュ",
+		"Coordinates/Coords": "座標",
+		"Player":             "プレイヤー",
+		"Native":             "ネイティブ関数",
+		"true/false":         "true/false",
+	},
+}
+
+var glossaryPatterns sync.Map
+
+/**
+ * @brief 列出术语表
+ * @param locale 语言代码，为空时列出所有语言
+ * @return []GlossaryEntry 按语言与术语排序的术语
+ * @return error 查询错误
+ */
+func ListGlossary(locale string) ([]GlossaryEntry, error) {
+	query := "SELECT id, term, locale, translation, note, updated_at FROM native_glossary"
+	args := []interface{}{}
+	if locale != "" {
+		query += " WHERE locale = ?"
+		args = append(args, locale)
+	}
+	query += " ORDER BY locale ASC, term ASC"
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []GlossaryEntry{}
+	for rows.Next() {
+		var e GlossaryEntry
+		var note sql.NullString
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&e.ID, &e.Term, &e.Locale, &e.Translation, &note, &updatedAt); err != nil {
+			continue
+		}
+		e.Note = note.String
+		if updatedAt.Valid {
+			e.UpdatedAt = &updatedAt.Time
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+/**
+ * @brief 获取单条术语
+ * @param id 术语 ID
+ * @return *GlossaryEntry 术语
+ * @return error 查询错误
+ */
+func GetGlossaryEntry(id int) (*GlossaryEntry, error) {
+	var e GlossaryEntry
+	var note sql.NullString
+	var updatedAt sql.NullTime
+	err := DB.QueryRow("SELECT id, term, locale, translation, note, updated_at FROM native_glossary WHERE id = ?", id).
+		Scan(&e.ID, &e.Term, &e.Locale, &e.Translation, &note, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	e.Note = note.String
+	if updatedAt.Valid {
+		e.UpdatedAt = &updatedAt.Time
+	}
+	return &e, nil
+}
+
+/**
+ * @brief 保存术语 (同一语言下术语已存在则更新译文，否则插入)
+ * @param e 术语，保存后回填 ID
+ * @return error 保存错误
+ */
+func SaveGlossaryEntry(e *GlossaryEntry) error {
+	e.Term = strings.TrimSpace(e.Term)
+	e.Translation = strings.TrimSpace(e.Translation)
+	if e.Term == "" || e.Translation == "" {
+		return fmt.Errorf("term and translation are required")
+	}
+
+	var id int
+	err := DB.QueryRow("SELECT id FROM native_glossary WHERE term = ? AND locale = ?", e.Term, e.Locale).Scan(&id)
+	if err == sql.ErrNoRows {
+		res, err := DB.Exec("INSERT INTO native_glossary (term, locale, translation, note) VALUES (?, ?, ?, ?)", e.Term, e.Locale, e.Translation, e.Note)
+		if err != nil {
+			return err
+		}
+		newID, _ := res.LastInsertId()
+		e.ID = int(newID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	_, err = DB.Exec("UPDATE native_glossary SET translation = ?, note = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", e.Translation, e.Note, id)
+	return err
+}
+
+/**
+ * @brief 按 ID 更新术语
+ * @param e 术语
+ * @return error 更新错误，术语不存在时返回 sql.ErrNoRows
+ */
+func UpdateGlossaryEntry(e *GlossaryEntry) error {
+	e.Term = strings.TrimSpace(e.Term)
+	e.Translation = strings.TrimSpace(e.Translation)
+	if e.Term == "" || e.Translation == "" {
+		return fmt.Errorf("term and translation are required")
+	}
+	res, err := DB.Exec("UPDATE native_glossary SET term = ?, locale = ?, translation = ?, note = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		e.Term, e.Locale, e.Translation, e.Note, e.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+/**
+ * @brief 删除术语
+ * @param id 术语 ID
+ * @return error 删除错误，术语不存在时返回 sql.ErrNoRows
+ */
+func DeleteGlossaryEntry(id int) error {
+	res, err := DB.Exec("DELETE FROM native_glossary WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+/**
+ * @brief 拆分以 "/" 分隔的多个写法
+ * @param s 术语或译文
+ * @return []string 写法列表
+ */
+func glossaryAliases(s string) []string {
+	var aliases []string
+	for _, a := range strings.Split(s, "/") {
+		if a = strings.TrimSpace(a); a != "" {
+			aliases = append(aliases, a)
+		}
+	}
+	return aliases
+}
+
+/**
+ * @brief 获取术语的匹配正则 (整词、忽略大小写、允许复数形式)
+ * @param term 术语
+ * @return *regexp.Regexp 匹配正则
+ */
+func glossaryPattern(term string) *regexp.Regexp {
+	if p, ok := glossaryPatterns.Load(term); ok {
+		return p.(*regexp.Regexp)
+	}
+	aliases := glossaryAliases(term)
+	quoted := make([]string, len(aliases))
+	for i, a := range aliases {
+		quoted[i] = regexp.QuoteMeta(a)
+	}
+	p := regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)(?:e?s)?\b`)
+	glossaryPatterns.Store(term, p)
+	return p
+}
+
+/**
+ * @brief 筛选出原文中出现的术语
+ * @param entries 术语表
+ * @param text 原文
+ * @return []GlossaryEntry 相关术语
+ */
+func MatchGlossary(entries []GlossaryEntry, text string) []GlossaryEntry {
+	var matched []GlossaryEntry
+	for _, e := range entries {
+		if glossaryPattern(e.Term).MatchString(text) {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
+
+/**
+ * @brief 检查译文是否使用了术语表中的某个译法
+ * @param e 术语
+ * @param translated 译文
+ * @return bool 是否符合术语表
+ */
+func glossaryRespected(e GlossaryEntry, translated string) bool {
+	lower := strings.ToLower(translated)
+	for _, t := range glossaryAliases(e.Translation) {
+		if strings.Contains(lower, strings.ToLower(t)) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * @brief 将描述与参数说明拼接为待匹配的原文
+ * @param desc 描述
+ * @param paramsJSON 参数 JSON
+ * @return string 原文
+ */
+func glossarySourceText(desc string, paramsJSON []byte) string {
+	parts := []string{desc}
+	var params []models.NativeParam
+	if len(paramsJSON) > 0 && json.Unmarshal(paramsJSON, &params) == nil {
+		for _, p := range params {
+			parts = append(parts, p.Description)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+/**
+ * @brief 检查已有翻译中违反术语表的条目
+ * @param locale 语言代码
+ * @return []GlossaryViolation 违规列表
+ * @return error 查询错误
+ */
+func CheckGlossary(locale string) ([]GlossaryViolation, error) {
+	entries, err := ListGlossary(locale)
+	if err != nil {
+		return nil, err
+	}
+	violations := []GlossaryViolation{}
+	if len(entries) == 0 {
+		return violations, nil
+	}
+
+	rows, err := DB.Query(`SELECT n.game, n.hash, n.name, n.description_original, n.params, nt.description, nt.params
+		FROM native_translations nt
+		JOIN natives n ON n.game = nt.game AND n.hash = nt.native_hash
+		WHERE nt.locale = ? AND nt.status <> ?
+		ORDER BY n.namespace ASC, n.name ASC`, locale, TranslationStatusNone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var game, hash string
+		var name, descOriginal, descTranslated sql.NullString
+		var paramsJSON, translatedParamsJSON []byte
+		if err := rows.Scan(&game, &hash, &name, &descOriginal, &paramsJSON, &descTranslated, &translatedParamsJSON); err != nil {
+			continue
+		}
+
+		translatedParts := []string{descTranslated.String}
+		var translatedParams map[string]string
+		if len(translatedParamsJSON) > 0 && json.Unmarshal(translatedParamsJSON, &translatedParams) == nil {
+			for _, text := range translatedParams {
+				translatedParts = append(translatedParts, text)
+			}
+		}
+		translated := strings.Join(translatedParts, "\n")
+
+		for _, e := range MatchGlossary(entries, glossarySourceText(descOriginal.String, paramsJSON)) {
+			if !glossaryRespected(e, translated) {
+				violations = append(violations, GlossaryViolation{
+					Game:       game,
+					NativeHash: hash,
+					Name:       name.String,
+					Locale:     locale,
+					Term:       e.Term,
+					Expected:   e.Translation,
+				})
+			}
+		}
+	}
+	return violations, nil
+}
+
+/**
+ * @brief 术语表为空时写入内置术语与配置中的 ai_glossary
+ */
+func seedGlossary() {
+	var count int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM native_glossary").Scan(&count); err != nil || count > 0 {
+		return
+	}
+
+	merged := make(map[string]map[string]string)
+	for locale, terms := range defaultGlossaries {
+		merged[locale] = make(map[string]string)
+		for term, translation := range terms {
+			merged[locale][term] = translation
+		}
+	}
+	if Config != nil {
+		for locale, terms := range Config.AiGlossary {
+			if merged[locale] == nil {
+				merged[locale] = make(map[string]string)
+			}
+			for term, translation := range terms {
+				if translation == "" {
+					delete(merged[locale], term)
+					continue
+				}
+				merged[locale][term] = translation
+			}
+		}
+	}
+
+	var entries []GlossaryEntry
+	for locale, terms := range merged {
+		for term, translation := range terms {
+			entries = append(entries, GlossaryEntry{Term: term, Locale: locale, Translation: translation})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Locale != entries[j].Locale {
+			return entries[i].Locale < entries[j].Locale
+		}
+		return entries[i].Term < entries[j].Term
+	})
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if _, err := tx.Exec("INSERT INTO native_glossary (term, locale, translation, note) VALUES (?, ?, ?, '')", e.Term, e.Locale, e.Translation); err != nil {
+			tx.Rollback()
+			log.Printf("Migration failed: %v", err)
+			return
+		}
+	}
+	if err := tx.Commit(); err == nil {
+		fmt.Printf("Migrated: Seeded %d glossary entries into 'native_glossary' table.\n", len(entries))
+	}
+}
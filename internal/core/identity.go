@@ -0,0 +1,414 @@
+package core
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// nativeDataTables 为翻译、任务、修订与提案表，与示例、源码一样按 (game, native_hash) 关联到具体游戏的函数
+var nativeDataTables = []string{"native_translations", "native_translation_jobs", "native_revisions", "native_proposals"}
+
+// ErrNativeAmbiguous 表示未指定游戏而该哈希存在于多个游戏中
+var ErrNativeAmbiguous = errors.New("native hash exists in more than one game")
+
+// nativeID 为函数的唯一标识
+type nativeID struct {
+	game, hash string
+}
+
+/**
+ * @brief 列出包含指定哈希的游戏
+ * @param hash 函数哈希
+ * @return []string 游戏代码 (按代码排序)
+ * @return error 查询错误
+ */
+func NativeGames(hash string) ([]string, error) {
+	rows, err := DB.Query("SELECT game FROM natives WHERE hash = ? ORDER BY game ASC", hash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	games := []string{}
+	for rows.Next() {
+		var game string
+		if err := rows.Scan(&game); err != nil {
+			return nil, err
+		}
+		games = append(games, game)
+	}
+	return games, rows.Err()
+}
+
+/**
+ * @brief 确定哈希所指的函数所属的游戏
+ * @param hash 函数哈希
+ * @param game 指定的游戏，为空时要求该哈希只存在于一个游戏中
+ * @return string 游戏代码
+ * @return error 函数不存在时返回 sql.ErrNoRows，未指定游戏且哈希存在于多个游戏时返回 ErrNativeAmbiguous
+ */
+func ResolveNativeGame(hash, game string) (string, error) {
+	if game != "" {
+		var exists bool
+		if err := DB.QueryRow("SELECT EXISTS(SELECT 1 FROM natives WHERE game = ? AND hash = ?)", game, hash).Scan(&exists); err != nil {
+			return "", err
+		}
+		if !exists {
+			return "", sql.ErrNoRows
+		}
+		return game, nil
+	}
+	games, err := NativeGames(hash)
+	if err != nil {
+		return "", err
+	}
+	switch len(games) {
+	case 0:
+		return "", sql.ErrNoRows
+	case 1:
+		return games[0], nil
+	default:
+		return "", ErrNativeAmbiguous
+	}
+}
+
+/**
+ * @brief 获取旧数据按哈希推断所属游戏的表达式，同一哈希存在于多个游戏时与迁移前一致，归属默认游戏
+ * @param table 子表名
+ * @return string SQL 表达式
+ */
+func legacyNativeGame(table string) string {
+	return fmt.Sprintf("COALESCE((SELECT NULLIF(n.game, '') FROM natives n WHERE n.hash = %[1]s.native_hash OR n.jhash = %[1]s.native_hash ORDER BY CASE WHEN n.game = '%[2]s' THEN 0 ELSE 1 END, n.game LIMIT 1), '%[2]s')", table, DefaultGame)
+}
+
+/**
+ * @brief 将函数标识从 hash 迁移为 (game, hash)，示例、源码、翻译、任务、修订与提案随之关联到具体游戏的函数
+ * @param dbType 数据库类型 (mysql 或 sqlite)
+ */
+func migrateNativeIdentity(dbType string) {
+	if dbType == "sqlite" {
+		var pkColumns int
+		DB.QueryRow("SELECT COUNT(*) FROM pragma_table_info('natives') WHERE pk > 0").Scan(&pkColumns)
+		var rebuilds []sqliteRebuild
+		if pkColumns == 1 {
+			gameExpr := fmt.Sprintf("COALESCE(NULLIF(game, ''), '%s')", DefaultGame)
+			// 子表需要在 natives 重建前读取旧的游戏列
+			rebuilds = []sqliteRebuild{
+				{"native_examples", map[string]string{"game": legacyNativeGame("native_examples")}},
+				{"native_sources", map[string]string{"game": legacyNativeGame("native_sources")}},
+				{"natives", map[string]string{"game": gameExpr}},
+			}
+		}
+		for _, table := range nativeDataTables {
+			if pkColumns == 1 || !columnExists(table, "game") {
+				rebuilds = append(rebuilds, sqliteRebuild{table, nativeDataGameExprs(table)})
+			}
+		}
+		if len(rebuilds) > 0 {
+			if err := rebuildSQLiteTables(rebuilds); err != nil {
+				log.Printf("Migration failed: %v", err)
+			} else {
+				fmt.Println("Migrated: Natives and their examples, sources, translations, jobs, revisions and proposals are now keyed by (game, hash).")
+			}
+		}
+		var searchGame int
+		if err := DB.QueryRow("SELECT COUNT(*) FROM pragma_table_info('native_search') WHERE name = 'game'").Scan(&searchGame); err == nil && searchGame == 0 {
+			recreateSearchTable(dbType)
+		}
+		return
+	}
+
+	// natives 的主键已在建表前由 migrateMySQLNativeKey 迁移
+	for _, table := range nativeDataTables {
+		if !tableExists(table) || columnExists(table, "game") {
+			continue
+		}
+		if err := migrateMySQLNativeData(table); err != nil {
+			log.Printf("Migration failed: %v", err)
+		} else {
+			fmt.Printf("Migrated: '%s' is now keyed by (game, native_hash).\n", table)
+		}
+	}
+	var searchGame int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'native_search' AND COLUMN_NAME = 'game'").Scan(&searchGame); err == nil && searchGame == 0 {
+		recreateSearchTable(dbType)
+	}
+}
+
+/**
+ * @brief 删除并按当前结构重建全文索引表，索引内容由 ensureSearchIndex 重新生成
+ * @param dbType 数据库类型
+ */
+func recreateSearchTable(dbType string) {
+	if _, err := DB.Exec("DROP TABLE IF EXISTS native_search"); err != nil {
+		log.Printf("Migration failed: %v", err)
+		return
+	}
+	if _, err := DB.Exec(tableSchema(dbType, "native_search")); err != nil {
+		log.Printf("Migration failed: %v", err)
+		return
+	}
+	fmt.Println("Migrated: Recreated 'native_search' with a 'game' column.")
+}
+
+/**
+ * @brief 获取指定表的建表语句
+ * @param dbType 数据库类型
+ * @param table 表名
+ * @return string 建表语句，不存在时为空字符串
+ */
+func tableSchema(dbType, table string) string {
+	for _, stmt := range schemaStatements(dbType) {
+		if strings.Contains(stmt, " IF NOT EXISTS "+table+" ") {
+			return stmt
+		}
+	}
+	return ""
+}
+
+/**
+ * @brief 获取表的列名
+ * @param tx 事务
+ * @param table 表名
+ * @return []string 列名
+ * @return error 查询错误
+ */
+func sqliteColumns(tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns = append(columns, name)
+	}
+	return columns, rows.Err()
+}
+
+/**
+ * @brief 按当前结构重建 SQLite 表 (SQLite 不支持修改主键与外键)，复制两边都有的列
+ * @param tx 事务
+ * @param table 表名
+ * @param exprs 新列的取值表达式，未指定的列直接复制同名旧列
+ * @return error 重建错误
+ */
+func rebuildSQLiteTable(tx *sql.Tx, table string, exprs map[string]string) error {
+	schema := tableSchema("sqlite", table)
+	if schema == "" {
+		return fmt.Errorf("no schema for %s", table)
+	}
+	oldColumns, err := sqliteColumns(tx, table)
+	if err != nil {
+		return err
+	}
+	old := make(map[string]bool, len(oldColumns))
+	for _, c := range oldColumns {
+		old[c] = true
+	}
+
+	tmp := table + "_new"
+	if _, err := tx.Exec(strings.Replace(schema, "IF NOT EXISTS "+table+" ", tmp+" ", 1)); err != nil {
+		return err
+	}
+	newColumns, err := sqliteColumns(tx, tmp)
+	if err != nil {
+		return err
+	}
+	var columns, values []string
+	for _, c := range newColumns {
+		if expr, ok := exprs[c]; ok {
+			columns, values = append(columns, c), append(values, expr)
+		} else if old[c] {
+			columns, values = append(columns, c), append(values, c)
+		}
+	}
+
+	steps := []string{
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", tmp, strings.Join(columns, ", "), strings.Join(values, ", "), table),
+		"DROP TABLE " + table,
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", tmp, table),
+	}
+	for _, step := range steps {
+		if _, err := tx.Exec(step); err != nil {
+			return fmt.Errorf("%s: %v", table, err)
+		}
+	}
+	return nil
+}
+
+// sqliteRebuild 为一张需要按当前结构重建的 SQLite 表及其新列的取值表达式
+type sqliteRebuild struct {
+	table string
+	exprs map[string]string
+}
+
+/**
+ * @brief 获取翻译、任务、修订与提案表迁移时新列的取值表达式
+ * @param table 表名
+ * @return map[string]string 列名到表达式的映射
+ */
+func nativeDataGameExprs(table string) map[string]string {
+	exprs := map[string]string{"game": legacyNativeGame(table)}
+	if table == "native_revisions" {
+		// 此前非默认游戏的示例修订以 example:<语言>@<游戏> 记录游戏
+		legacy := "field LIKE '" + RevisionFieldExamplePrefix + "%@%'"
+		exprs["game"] = fmt.Sprintf("CASE WHEN %s THEN substr(field, instr(field, '@') + 1) ELSE %s END", legacy, exprs["game"])
+		exprs["field"] = fmt.Sprintf("CASE WHEN %s THEN substr(field, 1, instr(field, '@') - 1) ELSE field END", legacy)
+	}
+	return exprs
+}
+
+/**
+ * @brief 在一个事务中重建 SQLite 表，删除翻译、任务、修订与提案表中不属于任何函数的行 (迁移前外键已失效)，并重新创建索引
+ * @param rebuilds 需要重建的表，按顺序执行
+ * @return error 迁移错误，出错时回滚
+ */
+func rebuildSQLiteTables(rebuilds []sqliteRebuild) error {
+	// 重建期间需要关闭外键检查，该设置在事务内无效
+	if _, err := DB.Exec("PRAGMA foreign_keys=OFF;"); err != nil {
+		return err
+	}
+	defer DB.Exec("PRAGMA foreign_keys=ON;")
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, r := range rebuilds {
+		if err := rebuildSQLiteTable(tx, r.table, r.exprs); err != nil {
+			return err
+		}
+	}
+	for _, table := range nativeDataTables {
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %[1]s WHERE NOT EXISTS (SELECT 1 FROM natives n WHERE n.game = %[1]s.game AND n.hash = %[1]s.native_hash)", table)); err != nil {
+			return fmt.Errorf("%s: %v", table, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// 重建后的表没有索引，重新执行建索引语句
+	for _, stmt := range schemaStatements("sqlite") {
+		if strings.HasPrefix(strings.TrimSpace(stmt), "CREATE INDEX") {
+			if _, err := DB.Exec(stmt); err != nil {
+				log.Printf("Warning: Failed to create index. Error: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+/**
+ * @brief 旧版 MySQL 数据库的 natives 仍以 hash 为主键时迁移为 (game, hash)，需在建表前执行
+ */
+func migrateMySQLNativeKey() {
+	var pkColumns int
+	DB.QueryRow("SELECT COUNT(*) FROM information_schema.KEY_COLUMN_USAGE WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'natives' AND CONSTRAINT_NAME = 'PRIMARY'").Scan(&pkColumns)
+	if pkColumns != 1 {
+		return
+	}
+	if err := migrateMySQLNatives(); err != nil {
+		log.Printf("Migration failed: %v", err)
+	} else {
+		fmt.Println("Migrated: 'natives' is now keyed by (game, hash).")
+	}
+}
+
+/**
+ * @brief 迁移 MySQL 中的函数表：去掉引用 natives(hash) 的外键，主键改为 (game, hash)，示例与源码增加 game 列并按 (game, native_hash) 关联 (其余子表由 migrateMySQLNativeData 迁移)
+ * @return error 迁移错误
+ */
+func migrateMySQLNatives() error {
+	rows, err := DB.Query("SELECT TABLE_NAME, CONSTRAINT_NAME FROM information_schema.REFERENTIAL_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND REFERENCED_TABLE_NAME = 'natives'")
+	if err != nil {
+		return err
+	}
+	var drops []string
+	for rows.Next() {
+		var table, constraint string
+		if err := rows.Scan(&table, &constraint); err != nil {
+			rows.Close()
+			return err
+		}
+		drops = append(drops, fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s", table, constraint))
+	}
+	rows.Close()
+
+	steps := append(drops,
+		fmt.Sprintf("UPDATE natives SET game = '%s' WHERE game IS NULL OR game = ''", DefaultGame),
+		fmt.Sprintf("ALTER TABLE natives MODIFY game varchar(20) NOT NULL DEFAULT '%s', DROP PRIMARY KEY, ADD PRIMARY KEY (game, hash), ADD KEY idx_hash (hash)", DefaultGame),
+	)
+	for _, step := range steps {
+		if _, err := DB.Exec(step); err != nil {
+			return err
+		}
+	}
+
+	for _, child := range []struct{ table, constraint string }{
+		{"native_examples", "fk_example_native"},
+		{"native_sources", "fk_source_native"},
+	} {
+		ensureColumn(child.table, "game", fmt.Sprintf("varchar(20) NOT NULL DEFAULT '%s' AFTER id", DefaultGame))
+		steps := []string{
+			fmt.Sprintf("UPDATE %s c JOIN natives n ON n.hash = c.native_hash SET c.game = n.game", child.table),
+			fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (game, native_hash) REFERENCES natives (game, hash) ON DELETE CASCADE", child.table, child.constraint),
+		}
+		for _, step := range steps {
+			if _, err := DB.Exec(step); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+/**
+ * @brief 迁移 MySQL 中的翻译、任务、修订或提案表：增加 game 列并按函数回填，删除不属于任何函数的行，按 (game, native_hash) 建立外键
+ * @param table 表名
+ * @return error 迁移错误
+ */
+func migrateMySQLNativeData(table string) error {
+	var position, key, constraint string
+	switch table {
+	case "native_translations":
+		position, key, constraint = "FIRST", "DROP PRIMARY KEY, ADD PRIMARY KEY (game, native_hash, locale)", "fk_translation_native"
+	case "native_translation_jobs":
+		position, key, constraint = "FIRST", "DROP PRIMARY KEY, ADD PRIMARY KEY (game, native_hash, locale)", "fk_job_native"
+	case "native_revisions":
+		position, key, constraint = "AFTER id", "ADD KEY idx_rev_native (game, native_hash)", "fk_revision_native"
+	case "native_proposals":
+		position, key, constraint = "AFTER id", "ADD KEY idx_prop_native (game, native_hash)", "fk_proposal_native"
+	default:
+		return fmt.Errorf("unknown table %s", table)
+	}
+	if !ensureColumn(table, "game", fmt.Sprintf("varchar(20) NOT NULL DEFAULT '%s' %s", DefaultGame, position)) {
+		return fmt.Errorf("%s: failed to add 'game' column", table)
+	}
+
+	steps := []string{fmt.Sprintf("UPDATE %s SET game = %s", table, legacyNativeGame(table))}
+	if table == "native_revisions" {
+		// 此前非默认游戏的示例修订以 example:<语言>@<游戏> 记录游戏 (SET 按顺序执行，game 取自原字段名)
+		steps = append(steps, fmt.Sprintf("UPDATE native_revisions SET game = SUBSTRING_INDEX(field, '@', -1), field = SUBSTRING_INDEX(field, '@', 1) WHERE field LIKE '%s%%@%%'", RevisionFieldExamplePrefix))
+	}
+	steps = append(steps,
+		fmt.Sprintf("DELETE t FROM %s t LEFT JOIN natives n ON n.game = t.game AND n.hash = t.native_hash WHERE n.hash IS NULL", table),
+		fmt.Sprintf("ALTER TABLE %s %s, ADD CONSTRAINT %s FOREIGN KEY (game, native_hash) REFERENCES natives (game, hash) ON DELETE CASCADE", table, key, constraint),
+	)
+	for _, step := range steps {
+		if _, err := DB.Exec(step); err != nil {
+			return fmt.Errorf("%s: %v", table, err)
+		}
+	}
+	return nil
+}
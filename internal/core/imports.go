@@ -0,0 +1,204 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxSummaryItems 为控制台摘要中每类变更最多列出的函数数量
+const maxSummaryItems = 10
+
+// NativeChange 为一次导入中单个函数的变更
+type NativeChange struct {
+	Hash         string `json:"hash"`
+	Namespace    string `json:"namespace"`
+	Name         string `json:"name"`
+	Game         string `json:"game,omitempty"`
+	OldName      string `json:"old_name,omitempty"`
+	OldSignature string `json:"old_signature,omitempty"`
+	NewSignature string `json:"new_signature,omitempty"`
+}
+
+// ImportReport 为一次函数数据导入的变更报告
+type ImportReport struct {
+	ID                 int64          `json:"id"`
+	Source             string         `json:"source"`
+	Game               string         `json:"game"`
+	StartedAt          time.Time      `json:"started_at"`
+	FinishedAt         time.Time      `json:"finished_at"`
+	Processed          int            `json:"processed"`
+	Unchanged          int            `json:"unchanged"`
+	Added              []NativeChange `json:"added"`
+	Removed            []NativeChange `json:"removed"`
+	Renamed            []NativeChange `json:"renamed"`
+	SignatureChanged   []NativeChange `json:"signature_changed"`
+	DescriptionChanged []NativeChange `json:"description_changed"`
+	// Conflicts 为哈希同时存在于其他游戏的函数，两者作为不同的函数分别保存，Game 为其他游戏
+	Conflicts []NativeChange `json:"conflicts"`
+	// Written 为本次写入 (新增或更新) 的函数哈希，用于清除缓存
+	Written []string `json:"-"`
+}
+
+// ImportRun 为导入记录的概要，不包含变更明细
+type ImportRun struct {
+	ID                 int64     `json:"id"`
+	Source             string    `json:"source"`
+	Game               string    `json:"game"`
+	StartedAt          time.Time `json:"started_at"`
+	FinishedAt         time.Time `json:"finished_at"`
+	Processed          int       `json:"processed"`
+	Added              int       `json:"added"`
+	Removed            int       `json:"removed"`
+	Renamed            int       `json:"renamed"`
+	SignatureChanged   int       `json:"signature_changed"`
+	DescriptionChanged int       `json:"description_changed"`
+}
+
+/**
+ * @brief 创建导入报告
+ * @param source 数据来源 (文件名)
+ * @param game 默认游戏
+ * @return *ImportReport 导入报告
+ */
+func NewImportReport(source, game string) *ImportReport {
+	return &ImportReport{
+		Source:             source,
+		Game:               game,
+		StartedAt:          time.Now(),
+		Added:              []NativeChange{},
+		Removed:            []NativeChange{},
+		Renamed:            []NativeChange{},
+		SignatureChanged:   []NativeChange{},
+		DescriptionChanged: []NativeChange{},
+		Conflicts:          []NativeChange{},
+	}
+}
+
+/**
+ * @brief 将各类变更按命名空间与函数名排序
+ */
+func (r *ImportReport) Sort() {
+	for _, changes := range [][]NativeChange{r.Added, r.Removed, r.Renamed, r.SignatureChanged, r.DescriptionChanged, r.Conflicts} {
+		sort.Slice(changes, func(i, j int) bool {
+			if changes[i].Namespace != changes[j].Namespace {
+				return changes[i].Namespace < changes[j].Namespace
+			}
+			return changes[i].Name < changes[j].Name
+		})
+	}
+}
+
+/**
+ * @brief 生成控制台摘要，每类变更最多列出 10 个函数
+ * @return string 多行摘要
+ */
+func (r *ImportReport) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Changes in %s: %d added, %d removed, %d renamed, %d signature changed, %d description changed, %d unchanged.",
+		r.Source, len(r.Added), len(r.Removed), len(r.Renamed), len(r.SignatureChanged), len(r.DescriptionChanged), r.Unchanged)
+
+	sections := []struct {
+		title   string
+		changes []NativeChange
+		format  func(NativeChange) string
+	}{
+		{"Added", r.Added, func(c NativeChange) string { return c.Name }},
+		{"Removed", r.Removed, func(c NativeChange) string { return c.Name }},
+		{"Renamed", r.Renamed, func(c NativeChange) string { return c.OldName + " -> " + c.Name }},
+		{"Signature changed", r.SignatureChanged, func(c NativeChange) string { return c.OldSignature + " -> " + c.NewSignature }},
+		{"Description changed", r.DescriptionChanged, func(c NativeChange) string { return c.Name }},
+		{"Hash also used by another game", r.Conflicts, func(c NativeChange) string { return c.Name + " (" + c.Game + ")" }},
+	}
+	for _, s := range sections {
+		if len(s.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s:", s.title)
+		for i, c := range s.changes {
+			if i == maxSummaryItems {
+				fmt.Fprintf(&b, "\n  ... and %d more", len(s.changes)-maxSummaryItems)
+				break
+			}
+			fmt.Fprintf(&b, "\n  %s %s", c.Hash, s.format(c))
+		}
+	}
+	return b.String()
+}
+
+/**
+ * @brief 保存导入记录并回填记录 ID
+ * @param r 导入报告
+ * @return error 写入错误
+ */
+func SaveImportRun(r *ImportReport) error {
+	report, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	res, err := DB.Exec(`INSERT INTO native_import_runs (source, game, started_at, finished_at, processed, added, removed, renamed, signature_changed, description_changed, report)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.Source, r.Game, r.StartedAt, r.FinishedAt, r.Processed, len(r.Added), len(r.Removed), len(r.Renamed), len(r.SignatureChanged), len(r.DescriptionChanged), string(report))
+	if err != nil {
+		return err
+	}
+	if r.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+	// 报告中的 ID 在插入后才确定
+	report, _ = json.Marshal(r)
+	_, err = DB.Exec("UPDATE native_import_runs SET report = ? WHERE id = ?", string(report), r.ID)
+	return err
+}
+
+/**
+ * @brief 列出最近的导入记录
+ * @param limit 最大数量
+ * @param game 游戏，为空时列出所有游戏
+ * @return []ImportRun 导入记录 (新的在前)
+ * @return error 查询错误
+ */
+func ListImportRuns(limit int, game string) ([]ImportRun, error) {
+	query := `SELECT id, source, game, started_at, finished_at, processed, added, removed, renamed, signature_changed, description_changed
+		FROM native_import_runs`
+	args := []interface{}{}
+	if game != "" {
+		query += " WHERE game = ?"
+		args = append(args, game)
+	}
+	rows, err := DB.Query(query+" ORDER BY id DESC LIMIT ?", append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []ImportRun{}
+	for rows.Next() {
+		var r ImportRun
+		if err := rows.Scan(&r.ID, &r.Source, &r.Game, &r.StartedAt, &r.FinishedAt, &r.Processed, &r.Added, &r.Removed, &r.Renamed, &r.SignatureChanged, &r.DescriptionChanged); err != nil {
+			return nil, err
+		}
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
+/**
+ * @brief 获取导入记录的完整报告
+ * @param id 记录 ID
+ * @return *ImportReport 导入报告，不存在时返回 sql.ErrNoRows
+ * @return error 查询错误
+ */
+func GetImportReport(id int64) (*ImportReport, error) {
+	var raw string
+	if err := DB.QueryRow("SELECT report FROM native_import_runs WHERE id = ?", id).Scan(&raw); err != nil {
+		return nil, err
+	}
+	var r ImportReport
+	if err := json.Unmarshal([]byte(raw), &r); err != nil {
+		return nil, fmt.Errorf("invalid import report %d: %v", id, err)
+	}
+	return &r, nil
+}
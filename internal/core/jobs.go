@@ -0,0 +1,121 @@
+package core
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+	JobReview  = "review"
+)
+
+type TranslationJob struct {
+	Game       string    `json:"game"`
+	NativeHash string    `json:"native_hash"`
+	Name       string    `json:"name"`
+	Locale     string    `json:"locale"`
+	Status     string    `json:"status"`
+	Attempts   int       `json:"attempts"`
+	LastError  string    `json:"last_error"`
+	TokensUsed int       `json:"tokens_used"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+/**
+ * @brief 确保翻译任务记录存在
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param locale 语言代码
+ * @return error 写入错误
+ */
+func ensureTranslationJob(game, hash, locale string) error {
+	var exists int
+	DB.QueryRow("SELECT COUNT(*) FROM native_translation_jobs WHERE game = ? AND native_hash = ? AND locale = ?", game, hash, locale).Scan(&exists)
+	if exists > 0 {
+		return nil
+	}
+	_, err := DB.Exec("INSERT INTO native_translation_jobs (game, native_hash, locale, status) VALUES (?, ?, ?, ?)", game, hash, locale, JobPending)
+	return err
+}
+
+/**
+ * @brief 标记翻译任务开始执行
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param locale 语言代码
+ * @return error 写入错误
+ */
+func StartTranslationJob(game, hash, locale string) error {
+	if err := ensureTranslationJob(game, hash, locale); err != nil {
+		return err
+	}
+	_, err := DB.Exec("UPDATE native_translation_jobs SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE game = ? AND native_hash = ? AND locale = ?",
+		JobRunning, game, hash, locale)
+	return err
+}
+
+/**
+ * @brief 记录翻译任务的执行结果，尝试次数与 token 用量累加
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param locale 语言代码
+ * @param status 任务状态 (JobDone 或 JobFailed)
+ * @param attempts 本次执行的尝试次数
+ * @param tokens 本次执行消耗的 token
+ * @param lastErr 最后一次错误，成功时为空
+ * @return error 写入错误
+ */
+func FinishTranslationJob(game, hash, locale, status string, attempts, tokens int, lastErr string) error {
+	if err := ensureTranslationJob(game, hash, locale); err != nil {
+		return err
+	}
+	var errValue interface{}
+	if lastErr != "" {
+		errValue = lastErr
+	}
+	_, err := DB.Exec("UPDATE native_translation_jobs SET status = ?, attempts = attempts + ?, tokens_used = tokens_used + ?, last_error = ?, updated_at = CURRENT_TIMESTAMP WHERE game = ? AND native_hash = ? AND locale = ?",
+		status, attempts, tokens, errValue, game, hash, locale)
+	return err
+}
+
+/**
+ * @brief 列出翻译任务
+ * @param locale 语言代码
+ * @param status 任务状态，为空时不过滤
+ * @return []TranslationJob 任务列表
+ * @return error 查询错误
+ */
+func ListTranslationJobs(locale, status string) ([]TranslationJob, error) {
+	query := `SELECT j.game, j.native_hash, COALESCE(n.name, ''), j.locale, j.status, j.attempts, j.last_error, j.tokens_used, j.updated_at
+		FROM native_translation_jobs j
+		LEFT JOIN natives n ON n.game = j.game AND n.hash = j.native_hash
+		WHERE j.locale = ?`
+	args := []interface{}{locale}
+	if status != "" {
+		query += " AND j.status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY j.updated_at DESC"
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []TranslationJob{}
+	for rows.Next() {
+		var j TranslationJob
+		var lastErr sql.NullString
+		if err := rows.Scan(&j.Game, &j.NativeHash, &j.Name, &j.Locale, &j.Status, &j.Attempts, &lastErr, &j.TokensUsed, &j.UpdatedAt); err != nil {
+			continue
+		}
+		j.LastError = lastErr.String
+		jobs = append(jobs, j)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,341 @@
+package core
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"nativedb/internal/models"
+)
+
+// MemoryFuzzyThreshold 为默认的模糊匹配阈值 (字符三元组 Dice 相似度)
+const MemoryFuzzyThreshold = 0.8
+
+type MemoryEntry struct {
+	Source     string `json:"source"`
+	Target     string `json:"translation"`
+	NativeHash string `json:"-"`
+}
+
+// TranslationMemory 为单个语言的翻译记忆索引
+type TranslationMemory struct {
+	exact    map[string]MemoryEntry
+	entries  []MemoryEntry
+	trigrams []map[string]struct{}
+}
+
+/**
+ * @brief 将描述拆分为段落 (以空行分隔，代码块保持完整)
+ * @param text 描述
+ * @return []string 段落列表
+ */
+func SegmentText(text string) []string {
+	var segments []string
+	var current []string
+	inCode := false
+
+	flush := func() {
+		if seg := strings.TrimSpace(strings.Join(current, "\n")); seg != "" {
+			segments = append(segments, seg)
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+		}
+		if !inCode && strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		current = append(current, line)
+	}
+	flush()
+	return segments
+}
+
+/**
+ * @brief 规范化段落 (合并空白)，用于精确匹配
+ * @param s 段落
+ * @return string 规范化后的段落
+ */
+func normalizeSegment(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+/**
+ * @brief 计算段落的记忆键
+ * @param s 段落
+ * @return string SHA-256 十六进制键
+ */
+func memoryKey(s string) string {
+	sum := sha256.Sum256([]byte(normalizeSegment(s)))
+	return hex.EncodeToString(sum[:])
+}
+
+/**
+ * @brief 计算字符三元组集合
+ * @param s 文本
+ * @return map[string]struct{} 三元组集合
+ */
+func segmentTrigrams(s string) map[string]struct{} {
+	runes := []rune(strings.ToLower(normalizeSegment(s)))
+	set := make(map[string]struct{})
+	if len(runes) < 3 {
+		set[string(runes)] = struct{}{}
+		return set
+	}
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = struct{}{}
+	}
+	return set
+}
+
+/**
+ * @brief 计算两个三元组集合的 Dice 相似度
+ * @param a 集合 a
+ * @param b 集合 b
+ * @return float64 相似度 (0-1)
+ */
+func diceSimilarity(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for k := range a {
+		if _, ok := b[k]; ok {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(a)+len(b))
+}
+
+/**
+ * @brief 写入一条翻译记忆 (存在则更新)
+ * @param locale 语言代码
+ * @param source 原文段落
+ * @param target 译文段落
+ * @param hash 来源函数哈希
+ * @return error 写入错误
+ */
+func saveMemoryEntry(locale, source, target, hash string) error {
+	source = strings.TrimSpace(source)
+	target = strings.TrimSpace(target)
+	if source == "" || target == "" {
+		return nil
+	}
+	key := memoryKey(source)
+
+	var exists int
+	DB.QueryRow("SELECT COUNT(*) FROM native_translation_memory WHERE locale = ? AND source_hash = ?", locale, key).Scan(&exists)
+	if exists == 0 {
+		_, err := DB.Exec("INSERT INTO native_translation_memory (locale, source_hash, source_text, target_text, native_hash) VALUES (?, ?, ?, ?, ?)",
+			locale, key, source, target, hash)
+		return err
+	}
+	_, err := DB.Exec("UPDATE native_translation_memory SET target_text = ?, native_hash = ?, updated_at = CURRENT_TIMESTAMP WHERE locale = ? AND source_hash = ?",
+		target, hash, locale, key)
+	return err
+}
+
+/**
+ * @brief 将原文与译文按段落和参数对齐
+ * @param descOriginal 英文描述
+ * @param paramsJSON 参数 JSON
+ * @param t 翻译
+ * @return [][2]string 对齐后的 [原文, 译文] 列表
+ */
+func alignTranslation(descOriginal string, paramsJSON []byte, t *Translation) [][2]string {
+	var pairs [][2]string
+	sources := SegmentText(descOriginal)
+	targets := SegmentText(t.Description)
+	// 段落数量一致时才能按位置对齐
+	if len(sources) > 0 && len(sources) == len(targets) {
+		for i := range sources {
+			pairs = append(pairs, [2]string{sources[i], targets[i]})
+		}
+	}
+
+	var params []models.NativeParam
+	if len(paramsJSON) > 0 && json.Unmarshal(paramsJSON, &params) == nil {
+		for _, p := range params {
+			if text := t.Params[p.Name]; text != "" && strings.TrimSpace(p.Description) != "" {
+				pairs = append(pairs, [2]string{p.Description, text})
+			}
+		}
+	}
+	return pairs
+}
+
+/**
+ * @brief 将已审核的翻译写入翻译记忆
+ * @param t 翻译
+ * @return error 写入错误
+ */
+func learnTranslation(t *Translation) error {
+	if t.Status != TranslationStatusReviewed {
+		return nil
+	}
+	var desc sql.NullString
+	var paramsJSON []byte
+	if err := DB.QueryRow("SELECT description_original, params FROM natives WHERE game = ? AND hash = ?", t.Game, t.NativeHash).Scan(&desc, &paramsJSON); err != nil {
+		return err
+	}
+	for _, pair := range alignTranslation(desc.String, paramsJSON, t) {
+		if err := saveMemoryEntry(t.Locale, pair[0], pair[1], t.NativeHash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+ * @brief 根据已审核的翻译重建指定语言的翻译记忆
+ * @param locale 语言代码
+ * @return int 记忆条目数量
+ * @return error 重建错误
+ */
+func RebuildTranslationMemory(locale string) (int, error) {
+	rows, err := DB.Query(`SELECT n.hash, n.description_original, n.params, nt.description, nt.params
+		FROM native_translations nt
+		JOIN natives n ON n.game = nt.game AND n.hash = nt.native_hash
+		WHERE nt.locale = ? AND nt.status = ?`, locale, TranslationStatusReviewed)
+	if err != nil {
+		return 0, err
+	}
+
+	type memoryRow struct {
+		source, target, hash string
+	}
+	entries := make(map[string]memoryRow)
+	for rows.Next() {
+		var hash string
+		var descOriginal, descTranslated sql.NullString
+		var paramsJSON, translatedParamsJSON []byte
+		if err := rows.Scan(&hash, &descOriginal, &paramsJSON, &descTranslated, &translatedParamsJSON); err != nil {
+			continue
+		}
+		t := &Translation{NativeHash: hash, Locale: locale, Description: descTranslated.String, Params: map[string]string{}}
+		if len(translatedParamsJSON) > 0 {
+			json.Unmarshal(translatedParamsJSON, &t.Params)
+		}
+		for _, pair := range alignTranslation(descOriginal.String, paramsJSON, t) {
+			source, target := strings.TrimSpace(pair[0]), strings.TrimSpace(pair[1])
+			if source != "" && target != "" {
+				entries[memoryKey(source)] = memoryRow{source, target, hash}
+			}
+		}
+	}
+	rows.Close()
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec("DELETE FROM native_translation_memory WHERE locale = ?", locale); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	stmt, err := tx.Prepare("INSERT INTO native_translation_memory (locale, source_hash, source_text, target_text, native_hash) VALUES (?, ?, ?, ?, ?)")
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for key, e := range entries {
+		if _, err := stmt.Exec(locale, key, e.source, e.target, e.hash); err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("failed to write translation memory: %v", err)
+		}
+	}
+	return len(entries), tx.Commit()
+}
+
+/**
+ * @brief 统计指定语言的翻译记忆条目数量
+ * @param locale 语言代码
+ * @return int 条目数量
+ */
+func CountTranslationMemory(locale string) int {
+	var count int
+	DB.QueryRow("SELECT COUNT(*) FROM native_translation_memory WHERE locale = ?", locale).Scan(&count)
+	return count
+}
+
+/**
+ * @brief 加载指定语言的翻译记忆到内存
+ * @param locale 语言代码
+ * @return *TranslationMemory 翻译记忆索引
+ * @return error 查询错误
+ */
+func LoadTranslationMemory(locale string) (*TranslationMemory, error) {
+	rows, err := DB.Query("SELECT source_hash, source_text, target_text, native_hash FROM native_translation_memory WHERE locale = ?", locale)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tm := &TranslationMemory{exact: make(map[string]MemoryEntry)}
+	for rows.Next() {
+		var key string
+		var e MemoryEntry
+		var hash sql.NullString
+		if err := rows.Scan(&key, &e.Source, &e.Target, &hash); err != nil {
+			continue
+		}
+		e.NativeHash = hash.String
+		tm.exact[key] = e
+		tm.entries = append(tm.entries, e)
+		tm.trigrams = append(tm.trigrams, segmentTrigrams(e.Source))
+	}
+	return tm, nil
+}
+
+/**
+ * @brief 获取记忆条目数量
+ * @return int 条目数量
+ */
+func (tm *TranslationMemory) Len() int {
+	return len(tm.entries)
+}
+
+/**
+ * @brief 精确查找段落的译文
+ * @param segment 原文段落
+ * @return string 译文
+ * @return bool 是否命中
+ */
+func (tm *TranslationMemory) Lookup(segment string) (string, bool) {
+	e, ok := tm.exact[memoryKey(segment)]
+	return e.Target, ok
+}
+
+/**
+ * @brief 模糊查找最相似的记忆条目
+ * @param segment 原文段落
+ * @param threshold 最低相似度
+ * @return *MemoryEntry 最相似的条目，未达到阈值时返回 nil
+ * @return float64 相似度
+ */
+func (tm *TranslationMemory) Fuzzy(segment string, threshold float64) (*MemoryEntry, float64) {
+	target := segmentTrigrams(segment)
+	best, bestScore := -1, 0.0
+	for i, tri := range tm.trigrams {
+		// 三元组数量相差过大时不可能达到阈值
+		if lo, hi := float64(len(tri)), float64(len(target)); 2*min(lo, hi)/(lo+hi) < threshold {
+			continue
+		}
+		if score := diceSimilarity(target, tri); score >= threshold && score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best == -1 {
+		return nil, 0
+	}
+	return &tm.entries[best], bestScore
+}
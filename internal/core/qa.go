@@ -0,0 +1,235 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"nativedb/internal/models"
+)
+
+const (
+	QACheckEmpty        = "empty"
+	QACheckEcho         = "echo"
+	QACheckCodeBlock    = "code_block"
+	QACheckInlineCode   = "inline_code"
+	QACheckHash         = "hash"
+	QACheckNumber       = "number"
+	QACheckParamName    = "param_name"
+	QACheckUnknownParam = "unknown_param"
+)
+
+// QAIssue 为自动质检发现的问题，Field 为 description 或 params.<参数名>
+type QAIssue struct {
+	Field  string `json:"field"`
+	Check  string `json:"check"`
+	Detail string `json:"detail"`
+}
+
+var (
+	qaCodeBlockPattern  = regexp.MustCompile("(?s)```[^\n]*\n?(.*?)```")
+	qaInlineCodePattern = regexp.MustCompile("`([^`\n]+)`")
+	qaHashPattern       = regexp.MustCompile(`(?i)\b0x[0-9a-f]+\b`)
+	qaNumberPattern     = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	qaWordPattern       = regexp.MustCompile(`[A-Za-z]{2,}`)
+)
+
+/**
+ * @brief 获取字符串列表中去重后的元素
+ * @param items 字符串列表
+ * @return []string 去重后的列表 (保持原顺序)
+ */
+func uniqueStrings(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	var result []string
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+/**
+ * @brief 检查原文中的代码、哈希与数字是否保留在译文中
+ * @param field 字段
+ * @param source 原文
+ * @param translated 译文
+ * @return []QAIssue 发现的问题
+ */
+func checkPreserved(field, source, translated string) []QAIssue {
+	var issues []QAIssue
+
+	for _, m := range qaCodeBlockPattern.FindAllStringSubmatch(source, -1) {
+		if code := strings.TrimSpace(m[1]); code != "" && !strings.Contains(translated, code) {
+			issues = append(issues, QAIssue{Field: field, Check: QACheckCodeBlock, Detail: "code block was changed or dropped"})
+		}
+	}
+	prose := qaCodeBlockPattern.ReplaceAllString(source, "")
+
+	var inline []string
+	for _, m := range qaInlineCodePattern.FindAllStringSubmatch(prose, -1) {
+		inline = append(inline, m[1])
+	}
+	for _, code := range uniqueStrings(inline) {
+		if !strings.Contains(translated, "`"+code+"`") {
+			issues = append(issues, QAIssue{Field: field, Check: QACheckInlineCode, Detail: fmt.Sprintf("missing `%s`", code)})
+		}
+	}
+
+	lowerTranslated := strings.ToLower(translated)
+	for _, hash := range uniqueStrings(qaHashPattern.FindAllString(prose, -1)) {
+		if !strings.Contains(lowerTranslated, strings.ToLower(hash)) {
+			issues = append(issues, QAIssue{Field: field, Check: QACheckHash, Detail: "missing " + hash})
+		}
+	}
+
+	// 哈希中的数字已由哈希检查覆盖
+	withoutHashes := qaHashPattern.ReplaceAllString(prose, "")
+	translatedNumbers := make(map[string]bool)
+	for _, n := range qaNumberPattern.FindAllString(qaHashPattern.ReplaceAllString(translated, ""), -1) {
+		translatedNumbers[n] = true
+	}
+	for _, n := range uniqueStrings(qaNumberPattern.FindAllString(withoutHashes, -1)) {
+		if !translatedNumbers[n] {
+			issues = append(issues, QAIssue{Field: field, Check: QACheckNumber, Detail: "missing number " + n})
+		}
+	}
+	return issues
+}
+
+/**
+ * @brief 检查译文是否为空或与英文原文相同
+ * @param field 字段
+ * @param source 原文
+ * @param translated 译文
+ * @param locale 目标语言
+ * @return []QAIssue 发现的问题
+ */
+func checkContent(field, source, translated, locale string) []QAIssue {
+	if strings.TrimSpace(source) == "" {
+		return nil
+	}
+	if strings.TrimSpace(translated) == "" {
+		return []QAIssue{{Field: field, Check: QACheckEmpty, Detail: "translation is empty"}}
+	}
+	// 目标语言为英语时原样输出是合理的；原文不足 3 个单词时可能本身无需翻译
+	if strings.HasPrefix(locale, "en") || len(qaWordPattern.FindAllString(source, -1)) < 3 {
+		return nil
+	}
+	if strings.EqualFold(normalizeSegment(source), normalizeSegment(translated)) {
+		return []QAIssue{{Field: field, Check: QACheckEcho, Detail: "translation is identical to the English source"}}
+	}
+	return nil
+}
+
+/**
+ * @brief 校验 AI 译文，检查空译文、原样返回、代码/哈希/数字/参数名丢失以及多余的参数
+ * @param locale 目标语言
+ * @param descOriginal 英文描述
+ * @param params 参数列表
+ * @param desc 描述译文
+ * @param translatedParams 参数译文 (可能包含模型返回的多余参数)
+ * @return []QAIssue 发现的问题，为空表示通过
+ */
+func ValidateTranslation(locale, descOriginal string, params []models.NativeParam, desc string, translatedParams map[string]string) []QAIssue {
+	var issues []QAIssue
+	if len(strings.TrimSpace(descOriginal)) > 1 {
+		issues = append(issues, checkContent("description", descOriginal, desc, locale)...)
+		issues = append(issues, checkPreserved("description", descOriginal, desc)...)
+	}
+
+	known := make(map[string]bool, len(params))
+	for _, p := range params {
+		known[p.Name] = true
+		// 描述中提到的参数名不应被翻译
+		if len(p.Name) >= 2 && regexp.MustCompile(`\b`+regexp.QuoteMeta(p.Name)+`\b`).MatchString(descOriginal) && !strings.Contains(desc, p.Name) {
+			issues = append(issues, QAIssue{Field: "description", Check: QACheckParamName, Detail: "missing parameter name " + p.Name})
+		}
+		if strings.TrimSpace(p.Description) == "" {
+			continue
+		}
+		field := "params." + p.Name
+		text := translatedParams[p.Name]
+		issues = append(issues, checkContent(field, p.Description, text, locale)...)
+		if text != "" {
+			issues = append(issues, checkPreserved(field, p.Description, text)...)
+		}
+	}
+
+	var unknown []string
+	for name := range translatedParams {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		issues = append(issues, QAIssue{Field: "params." + name, Check: QACheckUnknownParam, Detail: "no such parameter"})
+	}
+	return issues
+}
+
+/**
+ * @brief 将问题列表格式化为多行说明
+ * @param issues 问题列表
+ * @return string 说明
+ */
+func FormatQAIssues(issues []QAIssue) string {
+	lines := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		lines = append(lines, fmt.Sprintf("[%s] %s: %s", issue.Field, issue.Check, issue.Detail))
+	}
+	return strings.Join(lines, "\n")
+}
+
+/**
+ * @brief 将未通过质检的 AI 译文提交到审核队列，替换同一作者之前未审核的提案
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param locale 语言代码
+ * @param desc 描述译文
+ * @param translatedParams 参数译文
+ * @param author 提交者
+ * @param issues 质检问题
+ * @return error 提交错误
+ */
+func QueueTranslationReview(game, hash, locale, desc string, translatedParams map[string]string, author string, issues []QAIssue) error {
+	current, err := GetTranslation(game, hash, locale)
+	if err != nil {
+		return err
+	}
+	note := FormatQAIssues(issues)
+
+	descField, paramsField := DescriptionField(locale), ParamsField(locale)
+	if _, err := DB.Exec("DELETE FROM native_proposals WHERE game = ? AND native_hash = ? AND author = ? AND status = ? AND field IN (?, ?)",
+		game, hash, author, ProposalPending, descField, paramsField); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(desc) != "" {
+		if _, err := CreateProposalWithNote(game, hash, descField, current.Description, desc, author, note); err != nil {
+			return err
+		}
+	}
+	if len(translatedParams) > 0 {
+		names := make([]string, 0, len(translatedParams))
+		for name := range translatedParams {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		params := make([]models.NativeParam, 0, len(names))
+		for _, name := range names {
+			params = append(params, models.NativeParam{Name: name, DescriptionTranslated: translatedParams[name]})
+		}
+		base, _ := json.Marshal(current.Params)
+		value, _ := json.Marshal(params)
+		if _, err := CreateProposalWithNote(game, hash, paramsField, string(base), string(value), author, note); err != nil {
+			return err
+		}
+	}
+	return nil
+}
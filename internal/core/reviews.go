@@ -0,0 +1,280 @@
+package core
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	TranslationStatusNone     = 0
+	TranslationStatusAI       = 1
+	TranslationStatusReviewed = 2
+
+	ProposalPending  = "pending"
+	ProposalApproved = "approved"
+	ProposalRejected = "rejected"
+)
+
+type Proposal struct {
+	ID            int        `json:"id"`
+	Game          string     `json:"game"`
+	NativeHash    string     `json:"native_hash"`
+	NativeName    string     `json:"native_name"`
+	Field         string     `json:"field"`
+	BaseValue     string     `json:"base_value"`
+	Value         string     `json:"value"`
+	Author        string     `json:"author"`
+	Status        string     `json:"status"`
+	Reviewer      string     `json:"reviewer"`
+	ReviewComment string     `json:"review_comment"`
+	Note          string     `json:"note"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+}
+
+var (
+	// ErrProposalReviewed 表示提案已被审核 (包括并发审核中先完成的一方)
+	ErrProposalReviewed = errors.New("proposal already reviewed")
+	// ErrProposalStale 表示提案提交后内容已被修改，通过提案会覆盖较新的修改
+	ErrProposalStale = errors.New("content changed since the proposal was made")
+)
+
+const proposalColumns = `p.id, p.game, p.native_hash, COALESCE(n.name, ''), p.field, COALESCE(p.base_value, ''), p.value, p.author, p.status,
+	COALESCE(p.reviewer, ''), COALESCE(p.review_comment, ''), COALESCE(p.note, ''), p.created_at, p.reviewed_at`
+
+/**
+ * @brief 提交待审核的修改
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param field 字段名
+ * @param baseValue 提交时的当前内容
+ * @param value 提议的新内容
+ * @param author 提交者
+ * @return int64 提案 ID
+ * @return error 提交错误
+ */
+func CreateProposal(game, hash, field, baseValue, value, author string) (int64, error) {
+	return CreateProposalWithNote(game, hash, field, baseValue, value, author, "")
+}
+
+/**
+ * @brief 提交附带说明的待审核修改 (如自动质检发现的问题)
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param field 字段名
+ * @param baseValue 提交时的当前内容
+ * @param value 提议的新内容
+ * @param author 提交者
+ * @param note 说明
+ * @return int64 提案 ID
+ * @return error 提交错误
+ */
+func CreateProposalWithNote(game, hash, field, baseValue, value, author, note string) (int64, error) {
+	res, err := DB.Exec("INSERT INTO native_proposals (game, native_hash, field, base_value, value, author, status, note) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		game, hash, field, baseValue, value, author, ProposalPending, note)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+/**
+ * @brief 扫描提案行
+ * @param rows 查询结果
+ * @return []Proposal 提案列表
+ */
+func scanProposals(rows *sql.Rows) []Proposal {
+	proposals := []Proposal{}
+	for rows.Next() {
+		var p Proposal
+		var reviewedAt sql.NullTime
+		if err := rows.Scan(&p.ID, &p.Game, &p.NativeHash, &p.NativeName, &p.Field, &p.BaseValue, &p.Value, &p.Author, &p.Status,
+			&p.Reviewer, &p.ReviewComment, &p.Note, &p.CreatedAt, &reviewedAt); err != nil {
+			continue
+		}
+		if reviewedAt.Valid {
+			p.ReviewedAt = &reviewedAt.Time
+		}
+		proposals = append(proposals, p)
+	}
+	return proposals
+}
+
+/**
+ * @brief 获取单个提案
+ * @param id 提案 ID
+ * @return *Proposal 提案
+ * @return error 查询错误
+ */
+func GetProposal(id int) (*Proposal, error) {
+	rows, err := DB.Query("SELECT "+proposalColumns+" FROM native_proposals p LEFT JOIN natives n ON n.game = p.game AND n.hash = p.native_hash WHERE p.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	proposals := scanProposals(rows)
+	if len(proposals) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &proposals[0], nil
+}
+
+/**
+ * @brief 列出提案
+ * @param status 状态过滤，为空时不过滤
+ * @param game 游戏过滤，为空时不过滤
+ * @param hash 函数哈希过滤，为空时不过滤
+ * @return []Proposal 提案列表 (旧的在前)
+ * @return error 查询错误
+ */
+func ListProposals(status, game, hash string) ([]Proposal, error) {
+	query := "SELECT " + proposalColumns + " FROM native_proposals p LEFT JOIN natives n ON n.game = p.game AND n.hash = p.native_hash WHERE 1=1"
+	args := []interface{}{}
+	if status != "" {
+		query += " AND p.status = ?"
+		args = append(args, status)
+	}
+	if game != "" {
+		query += " AND p.game = ?"
+		args = append(args, game)
+	}
+	if hash != "" {
+		query += " AND p.native_hash = ?"
+		args = append(args, hash)
+	}
+	query += " ORDER BY p.id ASC"
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanProposals(rows), nil
+}
+
+/**
+ * @brief 更新提案审核结果
+ * @param id 提案 ID
+ * @param status 新状态
+ * @param reviewer 审核者
+ * @param comment 审核意见
+ * @return error 更新错误
+ */
+func ReviewProposal(id int, status, reviewer, comment string) error {
+	res, err := DB.Exec("UPDATE native_proposals SET status = ?, reviewer = ?, review_comment = ?, reviewed_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?",
+		status, reviewer, comment, id, ProposalPending)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n != 1 {
+		return ErrProposalReviewed
+	}
+	return nil
+}
+
+/**
+ * @brief 在事务中认领待通过的提案：标记为已通过，并确认提交时的内容仍是当前内容
+ * @param id 提案 ID
+ * @param reviewer 审核者
+ * @param comment 审核意见
+ * @return *Proposal 已认领的提案
+ * @return error 已被审核时返回 ErrProposalReviewed，内容已变化时返回 ErrProposalStale (均不修改提案)
+ */
+func ClaimProposal(id int, reviewer, comment string) (*Proposal, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// 先以条件更新占住提案，并发审核时只有一方的更新会生效
+	res, err := tx.Exec("UPDATE native_proposals SET status = ?, reviewer = ?, review_comment = ?, reviewed_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?",
+		ProposalApproved, reviewer, comment, id, ProposalPending)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n != 1 {
+		return nil, ErrProposalReviewed
+	}
+
+	var p Proposal
+	if err := tx.QueryRow("SELECT game, native_hash, field, COALESCE(base_value, ''), value, author FROM native_proposals WHERE id = ?", id).
+		Scan(&p.Game, &p.NativeHash, &p.Field, &p.BaseValue, &p.Value, &p.Author); err != nil {
+		return nil, err
+	}
+	p.ID, p.Status, p.Reviewer, p.ReviewComment = id, ProposalApproved, reviewer, comment
+
+	current, err := proposalCurrentValue(tx, &p)
+	if err != nil {
+		return nil, err
+	}
+	if !sameProposalValue(p.Field, p.BaseValue, current) {
+		return nil, ErrProposalStale
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+/**
+ * @brief 提案发布失败时将其恢复为待审核
+ * @param id 提案 ID
+ * @return error 更新错误
+ */
+func ReleaseProposal(id int) error {
+	_, err := DB.Exec("UPDATE native_proposals SET status = ?, reviewer = NULL, review_comment = NULL, reviewed_at = NULL WHERE id = ? AND status = ?",
+		ProposalPending, id, ProposalApproved)
+	return err
+}
+
+/**
+ * @brief 读取提案所修改字段的当前内容，格式与提交提案时的 base_value 一致
+ * @param tx 事务
+ * @param p 提案
+ * @return string 当前内容
+ * @return error 查询错误
+ */
+func proposalCurrentValue(tx *sql.Tx, p *Proposal) (string, error) {
+	field, locale := ParseTranslationField(p.Field)
+	var desc sql.NullString
+	var paramsJSON []byte
+	err := tx.QueryRow("SELECT description, params FROM native_translations WHERE game = ? AND native_hash = ? AND locale = ?", p.Game, p.NativeHash, locale).Scan(&desc, &paramsJSON)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+	if field == RevisionFieldParams {
+		return string(paramsJSON), nil
+	}
+	return desc.String, nil
+}
+
+/**
+ * @brief 比较提案的基准内容与当前内容，参数翻译按参数名比较
+ * @param field 字段名
+ * @param base 提案的基准内容
+ * @param current 当前内容
+ * @return bool 是否相同
+ */
+func sameProposalValue(field, base, current string) bool {
+	if name, _ := ParseTranslationField(field); name != RevisionFieldParams {
+		return base == current
+	}
+	var a, b map[string]string
+	json.Unmarshal([]byte(base), &a)
+	json.Unmarshal([]byte(current), &b)
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	for k, v := range b {
+		if a[k] != v {
+			return false
+		}
+	}
+	return true
+}
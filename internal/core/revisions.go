@@ -0,0 +1,172 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"nativedb/internal/models"
+)
+
+const (
+	RevisionFieldDescriptionCn = "description_cn"
+	RevisionFieldDescription   = "description"
+	RevisionFieldParams        = "params"
+	RevisionFieldExamplePrefix = "example:"
+)
+
+type Revision struct {
+	ID         int       `json:"id"`
+	Game       string    `json:"game"`
+	NativeHash string    `json:"native_hash"`
+	Field      string    `json:"field"`
+	OldValue   string    `json:"old_value"`
+	NewValue   string    `json:"new_value"`
+	Author     string    `json:"author"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+/**
+ * @brief 记录一次字段修改
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param field 字段名
+ * @param oldValue 修改前的值
+ * @param newValue 修改后的值
+ * @param author 修改者
+ * @return error 记录错误
+ */
+func RecordRevision(game, hash, field, oldValue, newValue, author string) error {
+	if oldValue == newValue {
+		return nil
+	}
+	if author == "" {
+		author = "System"
+	}
+	_, err := DB.Exec("INSERT INTO native_revisions (game, native_hash, field, old_value, new_value, author) VALUES (?, ?, ?, ?, ?, ?)",
+		game, hash, field, oldValue, newValue, author)
+	return err
+}
+
+/**
+ * @brief 获取描述翻译对应的修订字段名
+ * @param locale 语言代码
+ * @return string 字段名，默认语言沿用 description_cn
+ */
+func DescriptionField(locale string) string {
+	if locale == DefaultLocale {
+		return RevisionFieldDescriptionCn
+	}
+	return RevisionFieldDescription + "@" + locale
+}
+
+/**
+ * @brief 获取参数翻译对应的修订字段名
+ * @param locale 语言代码
+ * @return string 字段名，默认语言沿用 params
+ */
+func ParamsField(locale string) string {
+	if locale == DefaultLocale {
+		return RevisionFieldParams
+	}
+	return RevisionFieldParams + "@" + locale
+}
+
+/**
+ * @brief 解析翻译类修订字段名
+ * @param field 字段名
+ * @return string 基础字段 (RevisionFieldDescription 或 RevisionFieldParams)，非翻译字段返回空字符串
+ * @return string 语言代码
+ */
+func ParseTranslationField(field string) (string, string) {
+	switch field {
+	case RevisionFieldDescriptionCn:
+		return RevisionFieldDescription, DefaultLocale
+	case RevisionFieldParams:
+		return RevisionFieldParams, DefaultLocale
+	}
+	base, locale, ok := strings.Cut(field, "@")
+	if !ok || (base != RevisionFieldDescription && base != RevisionFieldParams) {
+		return "", ""
+	}
+	return base, locale
+}
+
+/**
+ * @brief 解析参数翻译修订内容，兼容完整参数数组与参数名映射两种格式
+ * @param value 修订内容
+ * @return map[string]string 参数名到翻译的映射
+ * @return error 解析错误
+ */
+func ParseParamsRevision(value string) (map[string]string, error) {
+	result := map[string]string{}
+	if strings.TrimSpace(value) == "" {
+		return result, nil
+	}
+	if strings.HasPrefix(strings.TrimSpace(value), "[") {
+		var params []models.NativeParam
+		if err := json.Unmarshal([]byte(value), &params); err != nil {
+			return nil, err
+		}
+		for _, p := range params {
+			if p.DescriptionCn != "" {
+				result[p.Name] = p.DescriptionCn
+			}
+		}
+		return result, nil
+	}
+	err := json.Unmarshal([]byte(value), &result)
+	return result, err
+}
+
+/**
+ * @brief 获取示例代码对应的修订字段名
+ * @param language 示例语言
+ * @return string 字段名
+ */
+func ExampleRevisionField(language string) string {
+	return RevisionFieldExamplePrefix + strings.ToLower(language)
+}
+
+/**
+ * @brief 获取单条修订记录
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param id 修订 ID
+ * @return *Revision 修订记录
+ * @return error 查询错误
+ */
+func GetRevision(game, hash string, id int) (*Revision, error) {
+	var r Revision
+	err := DB.QueryRow("SELECT id, game, native_hash, field, COALESCE(old_value, ''), COALESCE(new_value, ''), author, created_at FROM native_revisions WHERE id = ? AND game = ? AND native_hash = ?", id, game, hash).
+		Scan(&r.ID, &r.Game, &r.NativeHash, &r.Field, &r.OldValue, &r.NewValue, &r.Author, &r.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+/**
+ * @brief 列出函数的修订历史 (新的在前)
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @return []Revision 修订记录
+ * @return error 查询错误
+ */
+func ListRevisions(game, hash string) ([]Revision, error) {
+	rows, err := DB.Query("SELECT id, game, native_hash, field, COALESCE(old_value, ''), COALESCE(new_value, ''), author, created_at FROM native_revisions WHERE game = ? AND native_hash = ? ORDER BY id DESC", game, hash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []Revision{}
+	for rows.Next() {
+		var r Revision
+		if err := rows.Scan(&r.ID, &r.Game, &r.NativeHash, &r.Field, &r.OldValue, &r.NewValue, &r.Author, &r.CreatedAt); err != nil {
+			continue
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, nil
+}
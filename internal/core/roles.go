@@ -0,0 +1,46 @@
+package core
+
+const (
+	RoleViewer      = "viewer"
+	RoleContributor = "contributor"
+	RoleTranslator  = "translator"
+	RoleReviewer    = "reviewer"
+	RoleAdmin       = "admin"
+)
+
+// Roles 按权限从低到高排列，高等级角色拥有低等级角色的全部权限
+var Roles = []string{RoleViewer, RoleContributor, RoleTranslator, RoleReviewer, RoleAdmin}
+
+/**
+ * @brief 获取角色等级
+ * @param role 角色名
+ * @return int 角色等级，未知角色返回 -1
+ */
+func RoleLevel(role string) int {
+	for i, r := range Roles {
+		if r == role {
+			return i
+		}
+	}
+	return -1
+}
+
+/**
+ * @brief 检查角色名是否有效
+ * @param role 角色名
+ * @return bool 是否有效
+ */
+func ValidRole(role string) bool {
+	return RoleLevel(role) >= 0
+}
+
+/**
+ * @brief 检查角色是否满足最低权限要求
+ * @param role 当前角色
+ * @param required 需要的最低角色
+ * @return bool 是否满足
+ */
+func HasRole(role, required string) bool {
+	level := RoleLevel(role)
+	return level >= 0 && level >= RoleLevel(required)
+}
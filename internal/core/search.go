@@ -0,0 +1,386 @@
+package core
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"unicode"
+)
+
+const (
+	SnippetOpen  = "<mark>"
+	SnippetClose = "</mark>"
+	snippetWidth = 80
+
+	// FTS5 的 snippet() 不会转义文本，先用控制字符标记命中位置，转义后再替换为 <mark>
+	snippetOpenMarker  = "\x02"
+	snippetCloseMarker = "\x03"
+)
+
+var SearchIndexReady bool
+
+type searchParam struct {
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	DescriptionCn string `json:"description_cn"`
+}
+
+/**
+ * @brief 检查全文索引是否可用，数量不一致时重建
+ */
+func ensureSearchIndex() {
+	var indexed, total int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM native_search").Scan(&indexed); err != nil {
+		log.Printf("[Warning] Full-text index unavailable, falling back to LIKE search: %v", err)
+		SearchIndexReady = false
+		return
+	}
+	SearchIndexReady = true
+
+	DB.QueryRow("SELECT COUNT(*) FROM natives").Scan(&total)
+	if indexed != total {
+		fmt.Printf("Rebuilding full-text index (%d/%d indexed)...\n", indexed, total)
+		if err := RebuildSearchIndex(); err != nil {
+			log.Printf("[Warning] Failed to rebuild full-text index: %v", err)
+		}
+	}
+}
+
+/**
+ * @brief 将参数 JSON 展开为可索引的纯文本
+ * @param paramsJSON 参数 JSON
+ * @return string 参数文本
+ */
+func paramsSearchText(paramsJSON []byte) string {
+	var params []searchParam
+	if len(paramsJSON) == 0 || json.Unmarshal(paramsJSON, &params) != nil {
+		return ""
+	}
+	parts := make([]string, 0, len(params)*3)
+	for _, p := range params {
+		parts = append(parts, p.Name)
+		if p.Description != "" {
+			parts = append(parts, p.Description)
+		}
+		if p.DescriptionCn != "" {
+			parts = append(parts, p.DescriptionCn)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+/**
+ * @brief 将函数名中的下划线替换为空格，便于分词
+ * @param name 函数名
+ * @return string 可索引的名称
+ */
+func nameSearchText(name string) string {
+	return strings.ReplaceAll(name, "_", " ")
+}
+
+/**
+ * @brief 同步函数的全文索引
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @return error 同步错误
+ */
+func SyncSearchIndex(game, hash string) error {
+	if !SearchIndexReady {
+		return nil
+	}
+
+	extra := loadTranslationSearchText(game, hash)[nativeID{game, hash}]
+	rows, err := DB.Query("SELECT game, name, name_sp, description_original, description_cn, params FROM natives WHERE game = ? AND hash = ?", game, hash)
+	if err != nil {
+		return err
+	}
+	var pending []searchRow
+	for rows.Next() {
+		var game string
+		var name, nameSp, descOriginal, descCn sql.NullString
+		var paramsJSON []byte
+		if err := rows.Scan(&game, &name, &nameSp, &descOriginal, &descCn, &paramsJSON); err != nil {
+			rows.Close()
+			return err
+		}
+		pending = append(pending, newSearchRow(game, hash, name, nameSp, descOriginal, descCn, paramsJSON, extra))
+	}
+	rows.Close()
+
+	if _, err := DB.Exec("DELETE FROM native_search WHERE game = ? AND hash = ?", game, hash); err != nil {
+		return err
+	}
+	for _, r := range pending {
+		if _, err := DB.Exec(insertSearchSQL, r.game, r.hash, r.name, r.nameSp, r.descOriginal, r.descCn, r.params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertSearchSQL 为写入一条全文索引的语句
+const insertSearchSQL = "INSERT INTO native_search (game, hash, name, name_sp, description_original, description_cn, params_text) VALUES (?, ?, ?, ?, ?, ?, ?)"
+
+// searchRow 为待写入全文索引的一个函数
+type searchRow struct {
+	game, hash, name, nameSp, descOriginal, descCn, params string
+}
+
+/**
+ * @brief 生成函数的全文索引内容
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param name 函数名
+ * @param nameSp 单机版函数名
+ * @param descOriginal 英文描述
+ * @param descCn 中文描述
+ * @param paramsJSON 参数 JSON
+ * @param extra 其他语言的 [描述, 参数] 翻译文本
+ * @return searchRow 索引内容
+ */
+func newSearchRow(game, hash string, name, nameSp, descOriginal, descCn sql.NullString, paramsJSON []byte, extra [2]string) searchRow {
+	return searchRow{game, hash, nameSearchText(name.String), nameSearchText(nameSp.String), descOriginal.String,
+		joinSearchText(descCn.String, extra[0]), joinSearchText(paramsSearchText(paramsJSON), extra[1])}
+}
+
+/**
+ * @brief 加载非默认语言的翻译文本，用于全文索引
+ * @param game 游戏
+ * @param hash 函数哈希，为空时加载全部
+ * @return map[nativeID][2]string 函数到 [描述, 参数] 文本的映射
+ */
+func loadTranslationSearchText(game, hash string) map[nativeID][2]string {
+	query := "SELECT game, native_hash, description, params FROM native_translations WHERE locale <> ?"
+	args := []interface{}{DefaultLocale}
+	if hash != "" {
+		query += " AND game = ? AND native_hash = ?"
+		args = append(args, game, hash)
+	}
+
+	result := make(map[nativeID][2]string)
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return result
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id nativeID
+		var desc sql.NullString
+		var paramsJSON []byte
+		if err := rows.Scan(&id.game, &id.hash, &desc, &paramsJSON); err != nil {
+			continue
+		}
+		var params map[string]string
+		json.Unmarshal(paramsJSON, &params)
+		paramTexts := make([]string, 0, len(params))
+		for _, text := range params {
+			paramTexts = append(paramTexts, text)
+		}
+		cur := result[id]
+		result[id] = [2]string{joinSearchText(cur[0], desc.String), joinSearchText(cur[1], strings.Join(paramTexts, " "))}
+	}
+	return result
+}
+
+/**
+ * @brief 以空格拼接非空文本
+ * @param a 文本 a
+ * @param b 文本 b
+ * @return string 拼接结果
+ */
+func joinSearchText(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	return a + " " + b
+}
+
+/**
+ * @brief 重建整个全文索引
+ * @return error 重建错误
+ */
+func RebuildSearchIndex() error {
+	if !SearchIndexReady {
+		return nil
+	}
+
+	// 先加载翻译文本，避免在遍历 natives 游标时再次查询 (SQLite 仅有一个连接)
+	translations := loadTranslationSearchText("", "")
+	rows, err := DB.Query("SELECT game, hash, name, name_sp, description_original, description_cn, params FROM natives")
+	if err != nil {
+		return err
+	}
+
+	var pending []searchRow
+	for rows.Next() {
+		var game, hash string
+		var name, nameSp, descOriginal, descCn sql.NullString
+		var paramsJSON []byte
+		if err := rows.Scan(&game, &hash, &name, &nameSp, &descOriginal, &descCn, &paramsJSON); err != nil {
+			continue
+		}
+		pending = append(pending, newSearchRow(game, hash, name, nameSp, descOriginal, descCn, paramsJSON, translations[nativeID{game, hash}]))
+	}
+	rows.Close()
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM native_search"); err != nil {
+		tx.Rollback()
+		return err
+	}
+	stmt, err := tx.Prepare(insertSearchSQL)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, r := range pending {
+		if _, err := stmt.Exec(r.game, r.hash, r.name, r.nameSp, r.descOriginal, r.descCn, r.params); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+/**
+ * @brief 将用户输入拆分为搜索词
+ * @param q 用户输入
+ * @return []string 搜索词
+ */
+func SearchTerms(q string) []string {
+	return strings.FieldsFunc(q, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+/**
+ * @brief 构建全文检索子查询
+ * @param q 用户输入
+ * @return string 子查询 SQL，返回 game、hash、score (越小越相关) 与 snippet 列
+ * @return []interface{} 查询参数
+ * @return bool 是否可用
+ */
+func FulltextSubquery(q string) (string, []interface{}, bool) {
+	terms := SearchTerms(q)
+	if !SearchIndexReady || len(terms) == 0 {
+		return "", nil, false
+	}
+
+	if Config.DbType == "sqlite" {
+		quoted := make([]string, len(terms))
+		for i, t := range terms {
+			quoted[i] = `"` + t + `"*`
+		}
+		query := `SELECT game, hash, bm25(native_search) AS score, snippet(native_search, -1, char(2), char(3), '...', 16) AS snippet
+			FROM native_search WHERE native_search MATCH ?`
+		return query, []interface{}{strings.Join(quoted, " ")}, true
+	}
+
+	query := `SELECT game, hash,
+			-MATCH(name, name_sp, description_original, description_cn, params_text) AGAINST (? IN NATURAL LANGUAGE MODE) AS score,
+			CONCAT_WS(' ', description_original, description_cn, params_text) AS snippet
+		FROM native_search
+		WHERE MATCH(name, name_sp, description_original, description_cn, params_text) AGAINST (? IN NATURAL LANGUAGE MODE)`
+	joined := strings.Join(terms, " ")
+	return query, []interface{}{joined, joined}, true
+}
+
+/**
+ * @brief 将 FTS5 snippet() 返回的片段转义为 HTML，并把命中标记替换为 <mark>
+ * @param raw snippet() 返回的片段
+ * @return string 可直接作为 HTML 渲染的高亮片段
+ */
+func HighlightSnippet(raw string) string {
+	escaped := html.EscapeString(raw)
+	return strings.NewReplacer(snippetOpenMarker, SnippetOpen, snippetCloseMarker, SnippetClose).Replace(escaped)
+}
+
+/**
+ * @brief 从文本中截取包含搜索词的片段并高亮，文本会先做 HTML 转义
+ * @param text 原始文本
+ * @param terms 搜索词
+ * @return string 可直接作为 HTML 渲染的高亮片段
+ */
+func MakeSnippet(text string, terms []string) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(runes) != len(lower) {
+		lower = runes
+	}
+
+	first := -1
+	for _, t := range terms {
+		if idx := indexRunes(lower, []rune(strings.ToLower(t))); idx != -1 && (first == -1 || idx < first) {
+			first = idx
+		}
+	}
+	if first == -1 {
+		first = 0
+	}
+
+	start := first - snippetWidth/4
+	if start < 0 {
+		start = 0
+	}
+	end := start + snippetWidth
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("...")
+	}
+	for i := start; i < end; {
+		matched := 0
+		for _, t := range terms {
+			tr := []rune(strings.ToLower(t))
+			if len(tr) > 0 && i+len(tr) <= end && string(lower[i:i+len(tr)]) == string(tr) && len(tr) > matched {
+				matched = len(tr)
+			}
+		}
+		if matched > 0 {
+			b.WriteString(SnippetOpen)
+			b.WriteString(html.EscapeString(string(runes[i : i+matched])))
+			b.WriteString(SnippetClose)
+			i += matched
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+		i++
+	}
+	if end < len(runes) {
+		b.WriteString("...")
+	}
+	return b.String()
+}
+
+/**
+ * @brief 在 rune 切片中查找子串
+ * @param s 源切片
+ * @param sub 子串
+ * @return int 起始位置，未找到返回 -1
+ */
+func indexRunes(s, sub []rune) int {
+	if len(sub) == 0 {
+		return -1
+	}
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if string(s[i:i+len(sub)]) == string(sub) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	SyncRunning   = "running"
+	SyncUnchanged = "unchanged"
+	SyncImported  = "imported"
+	SyncFailed    = "failed"
+)
+
+// SyncFile 为一次同步中单个数据文件的检查结果
+type SyncFile struct {
+	Name    string `json:"name"`
+	Updated bool   `json:"updated"`
+	Error   string `json:"error,omitempty"`
+}
+
+// SyncRun 为一次上游数据同步的记录
+type SyncRun struct {
+	ID          int64      `json:"id"`
+	TriggeredBy string     `json:"triggered_by"`
+	Status      string     `json:"status"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	Files       []SyncFile `json:"files"`
+	ImportRuns  []int64    `json:"import_runs"`
+	Error       string     `json:"error,omitempty"`
+	// Written 为本次同步写入的函数哈希，用于清除缓存
+	Written []string `json:"-"`
+}
+
+/**
+ * @brief 保存同步记录并回填记录 ID
+ * @param r 同步记录
+ * @return error 写入错误
+ */
+func SaveSyncRun(r *SyncRun) error {
+	files, _ := json.Marshal(r.Files)
+	imports, _ := json.Marshal(r.ImportRuns)
+	res, err := DB.Exec("INSERT INTO native_sync_runs (triggered_by, status, started_at, finished_at, files, import_runs, error) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		r.TriggeredBy, r.Status, r.StartedAt, r.FinishedAt, string(files), string(imports), r.Error)
+	if err != nil {
+		return err
+	}
+	r.ID, err = res.LastInsertId()
+	return err
+}
+
+/**
+ * @brief 列出最近的同步记录
+ * @param limit 最大数量
+ * @return []SyncRun 同步记录 (新的在前)
+ * @return error 查询错误
+ */
+func ListSyncRuns(limit int) ([]SyncRun, error) {
+	rows, err := DB.Query("SELECT id, triggered_by, status, started_at, finished_at, COALESCE(files, ''), COALESCE(import_runs, ''), COALESCE(error, '') FROM native_sync_runs ORDER BY id DESC LIMIT ?", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []SyncRun{}
+	for rows.Next() {
+		var r SyncRun
+		var files, imports string
+		if err := rows.Scan(&r.ID, &r.TriggeredBy, &r.Status, &r.StartedAt, &r.FinishedAt, &files, &imports, &r.Error); err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(files), &r.Files)
+		json.Unmarshal([]byte(imports), &r.ImportRuns)
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
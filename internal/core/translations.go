@@ -0,0 +1,434 @@
+package core
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"nativedb/internal/models"
+)
+
+// DefaultLocale 为历史遗留的中文翻译语言，其内容同时镜像到 natives.description_cn 与 params 中
+const DefaultLocale = "zh-CN"
+
+var localePattern = regexp.MustCompile(`^([a-zA-Z]{2,3})(?:[-_]([a-zA-Z]{2,4}))?$`)
+
+type Translation struct {
+	Game        string            `json:"game"`
+	NativeHash  string            `json:"native_hash"`
+	Locale      string            `json:"locale"`
+	Description string            `json:"description"`
+	Params      map[string]string `json:"params"`
+	Status      int               `json:"status"`
+	SourceHash  string            `json:"source_hash"`
+	Outdated    bool              `json:"outdated"`
+	UpdatedAt   *time.Time        `json:"updated_at"`
+}
+
+type OutdatedTranslation struct {
+	Game       string     `json:"game"`
+	NativeHash string     `json:"native_hash"`
+	Name       string     `json:"name"`
+	Namespace  string     `json:"namespace"`
+	Locale     string     `json:"locale"`
+	Status     int        `json:"status"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+}
+
+type LocaleStat struct {
+	Locale     string `json:"locale"`
+	Translated int    `json:"translated"`
+}
+
+/**
+ * @brief 规范化语言代码 (如 zh-tw -> zh-TW, pt_br -> pt-BR)
+ * @param locale 语言代码
+ * @return string 规范化后的语言代码
+ * @return bool 是否有效
+ */
+func NormalizeLocale(locale string) (string, bool) {
+	m := localePattern.FindStringSubmatch(strings.TrimSpace(locale))
+	if m == nil {
+		return "", false
+	}
+	lang := strings.ToLower(m[1])
+	if m[2] == "" {
+		return lang, true
+	}
+	region := m[2]
+	if len(region) == 4 {
+		// 书写系统，如 zh-Hant
+		region = strings.ToUpper(region[:1]) + strings.ToLower(region[1:])
+	} else {
+		region = strings.ToUpper(region)
+	}
+	return lang + "-" + region, true
+}
+
+/**
+ * @brief 计算原文指纹 (英文描述与参数说明)，用于判断翻译是否过期
+ * @param descOriginal 英文描述
+ * @param paramsJSON 参数 JSON
+ * @return string SHA-256 十六进制指纹
+ */
+func SourceFingerprint(descOriginal string, paramsJSON []byte) string {
+	h := sha256.New()
+	h.Write([]byte(strings.TrimSpace(descOriginal)))
+	var params []models.NativeParam
+	if len(paramsJSON) > 0 && json.Unmarshal(paramsJSON, &params) == nil {
+		for _, p := range params {
+			h.Write([]byte{0})
+			h.Write([]byte(p.Name))
+			h.Write([]byte{'='})
+			h.Write([]byte(strings.TrimSpace(p.Description)))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+/**
+ * @brief 获取函数当前原文的指纹
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @return string 指纹，函数不存在时返回空字符串
+ */
+func CurrentSourceFingerprint(game, hash string) string {
+	var desc sql.NullString
+	var paramsJSON []byte
+	if err := DB.QueryRow("SELECT description_original, params FROM natives WHERE game = ? AND hash = ?", game, hash).Scan(&desc, &paramsJSON); err != nil {
+		return ""
+	}
+	return SourceFingerprint(desc.String, paramsJSON)
+}
+
+/**
+ * @brief 获取函数在指定语言下的翻译，不存在时返回空翻译
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param locale 语言代码
+ * @return *Translation 翻译
+ * @return error 查询错误
+ */
+func GetTranslation(game, hash, locale string) (*Translation, error) {
+	t := &Translation{Game: game, NativeHash: hash, Locale: locale, Params: map[string]string{}}
+	var desc sql.NullString
+	var paramsJSON []byte
+	var sourceHash sql.NullString
+	var updatedAt sql.NullTime
+	err := DB.QueryRow("SELECT description, params, status, source_hash, outdated, updated_at FROM native_translations WHERE game = ? AND native_hash = ? AND locale = ?", game, hash, locale).
+		Scan(&desc, &paramsJSON, &t.Status, &sourceHash, &t.Outdated, &updatedAt)
+	if err == sql.ErrNoRows {
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.Description = desc.String
+	t.SourceHash = sourceHash.String
+	if len(paramsJSON) > 0 {
+		json.Unmarshal(paramsJSON, &t.Params)
+	}
+	if updatedAt.Valid {
+		t.UpdatedAt = &updatedAt.Time
+	}
+	return t, nil
+}
+
+/**
+ * @brief 列出函数的所有语言翻译
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @return []Translation 翻译列表
+ * @return error 查询错误
+ */
+func ListTranslations(game, hash string) ([]Translation, error) {
+	rows, err := DB.Query("SELECT locale, description, params, status, source_hash, outdated, updated_at FROM native_translations WHERE game = ? AND native_hash = ? ORDER BY locale ASC", game, hash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := []Translation{}
+	for rows.Next() {
+		t := Translation{Game: game, NativeHash: hash, Params: map[string]string{}}
+		var desc sql.NullString
+		var paramsJSON []byte
+		var sourceHash sql.NullString
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&t.Locale, &desc, &paramsJSON, &t.Status, &sourceHash, &t.Outdated, &updatedAt); err != nil {
+			continue
+		}
+		t.Description = desc.String
+		t.SourceHash = sourceHash.String
+		if len(paramsJSON) > 0 {
+			json.Unmarshal(paramsJSON, &t.Params)
+		}
+		if updatedAt.Valid {
+			t.UpdatedAt = &updatedAt.Time
+		}
+		translations = append(translations, t)
+	}
+	return translations, nil
+}
+
+/**
+ * @brief 统计各语言已有翻译的函数数量
+ * @return []LocaleStat 语言统计
+ * @return error 查询错误
+ */
+func ListLocales() ([]LocaleStat, error) {
+	rows, err := DB.Query("SELECT locale, COUNT(*) FROM native_translations WHERE status <> ? GROUP BY locale ORDER BY locale ASC", TranslationStatusNone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []LocaleStat{}
+	for rows.Next() {
+		var s LocaleStat
+		if err := rows.Scan(&s.Locale, &s.Translated); err == nil {
+			stats = append(stats, s)
+		}
+	}
+	return stats, nil
+}
+
+/**
+ * @brief 保存翻译 (存在则更新，否则插入)，同时记录当前原文指纹并清除过期标记
+ * @param t 翻译
+ * @return error 保存错误
+ */
+func SaveTranslation(t *Translation) error {
+	if t.Params == nil {
+		t.Params = map[string]string{}
+	}
+	paramsJSON, _ := json.Marshal(t.Params)
+	t.SourceHash = CurrentSourceFingerprint(t.Game, t.NativeHash)
+	t.Outdated = false
+
+	var exists int
+	DB.QueryRow("SELECT COUNT(*) FROM native_translations WHERE game = ? AND native_hash = ? AND locale = ?", t.Game, t.NativeHash, t.Locale).Scan(&exists)
+
+	var err error
+	if exists == 0 {
+		_, err = DB.Exec("INSERT INTO native_translations (game, native_hash, locale, description, params, status, source_hash, outdated) VALUES (?, ?, ?, ?, ?, ?, ?, 0)",
+			t.Game, t.NativeHash, t.Locale, t.Description, string(paramsJSON), t.Status, t.SourceHash)
+	} else {
+		_, err = DB.Exec("UPDATE native_translations SET description = ?, params = ?, status = ?, source_hash = ?, outdated = 0, updated_at = CURRENT_TIMESTAMP WHERE game = ? AND native_hash = ? AND locale = ?",
+			t.Description, string(paramsJSON), t.Status, t.SourceHash, t.Game, t.NativeHash, t.Locale)
+	}
+	if err != nil {
+		return err
+	}
+
+	if t.Locale == DefaultLocale {
+		if err := mirrorDefaultLocale(t); err != nil {
+			return err
+		}
+	}
+	return learnTranslation(t)
+}
+
+/**
+ * @brief 将默认语言的翻译同步到 natives 表的旧字段
+ * @param t 翻译
+ * @return error 同步错误
+ */
+func mirrorDefaultLocale(t *Translation) error {
+	var paramsJSON []byte
+	if err := DB.QueryRow("SELECT params FROM natives WHERE game = ? AND hash = ?", t.Game, t.NativeHash).Scan(&paramsJSON); err != nil {
+		return err
+	}
+	var params []models.NativeParam
+	if len(paramsJSON) > 0 {
+		json.Unmarshal(paramsJSON, &params)
+	}
+	for i := range params {
+		params[i].DescriptionCn = t.Params[params[i].Name]
+	}
+	if params == nil {
+		params = []models.NativeParam{}
+	}
+	finalJSON, _ := json.Marshal(params)
+
+	_, err := DB.Exec("UPDATE natives SET description_cn = ?, params = ?, translation_status = ? WHERE game = ? AND hash = ?", t.Description, finalJSON, t.Status, t.Game, t.NativeHash)
+	return err
+}
+
+/**
+ * @brief 将 natives 表中已有的中文翻译迁移到 native_translations 表
+ */
+func migrateTranslations() {
+	var count int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM native_translations").Scan(&count); err != nil || count > 0 {
+		return
+	}
+
+	rows, err := DB.Query("SELECT game, hash, description_cn, params, translation_status FROM natives WHERE translation_status <> 0 OR (description_cn IS NOT NULL AND description_cn <> '')")
+	if err != nil {
+		return
+	}
+
+	var pending []Translation
+	for rows.Next() {
+		var game, hash string
+		var desc sql.NullString
+		var paramsJSON []byte
+		var status sql.NullInt64
+		if err := rows.Scan(&game, &hash, &desc, &paramsJSON, &status); err != nil {
+			continue
+		}
+		t := Translation{Game: game, NativeHash: hash, Locale: DefaultLocale, Description: desc.String, Params: map[string]string{}, Status: int(status.Int64)}
+		var params []models.NativeParam
+		if len(paramsJSON) > 0 && json.Unmarshal(paramsJSON, &params) == nil {
+			for _, p := range params {
+				if p.DescriptionCn != "" {
+					t.Params[p.Name] = p.DescriptionCn
+				}
+			}
+		}
+		pending = append(pending, t)
+	}
+	rows.Close()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return
+	}
+	for _, t := range pending {
+		paramsJSON, _ := json.Marshal(t.Params)
+		if _, err := tx.Exec("INSERT INTO native_translations (game, native_hash, locale, description, params, status) VALUES (?, ?, ?, ?, ?, ?)",
+			t.Game, t.NativeHash, t.Locale, t.Description, string(paramsJSON), t.Status); err != nil {
+			tx.Rollback()
+			log.Printf("Migration failed: %v", err)
+			return
+		}
+	}
+	if err := tx.Commit(); err == nil {
+		fmt.Printf("Migrated: Copied %d '%s' translations into 'native_translations' table.\n", len(pending), DefaultLocale)
+	}
+}
+
+// markOutdatedSQL 将指纹与新原文不一致的翻译标记为过期
+const markOutdatedSQL = "UPDATE native_translations SET outdated = 1 WHERE game = ? AND native_hash = ? AND status <> ? AND outdated = 0 AND source_hash IS NOT NULL AND source_hash <> '' AND source_hash <> ?"
+
+/**
+ * @brief 原文变化后在导入事务中将基于旧原文的翻译标记为过期
+ * @param tx 导入事务
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param fingerprint 新原文的指纹
+ * @return int64 新标记为过期的翻译数量
+ * @return error 更新错误
+ */
+func MarkOutdatedTranslations(tx *sql.Tx, game, hash, fingerprint string) (int64, error) {
+	res, err := tx.Exec(markOutdatedSQL, game, hash, TranslationStatusNone, fingerprint)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+/**
+ * @brief 列出过期的翻译
+ * @param locale 语言代码，为空时列出所有语言
+ * @param game 游戏，为空时列出所有游戏
+ * @return []OutdatedTranslation 过期翻译列表
+ * @return error 查询错误
+ */
+func ListOutdatedTranslations(locale, game string) ([]OutdatedTranslation, error) {
+	query := `SELECT nt.game, nt.native_hash, n.name, n.namespace, nt.locale, nt.status, nt.updated_at
+		FROM native_translations nt
+		JOIN natives n ON n.game = nt.game AND n.hash = nt.native_hash
+		WHERE nt.outdated = 1`
+	args := []interface{}{}
+	if locale != "" {
+		query += " AND nt.locale = ?"
+		args = append(args, locale)
+	}
+	if game != "" {
+		query += " AND nt.game = ?"
+		args = append(args, game)
+	}
+	query += " ORDER BY nt.locale ASC, n.namespace ASC, n.name ASC"
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []OutdatedTranslation{}
+	for rows.Next() {
+		var o OutdatedTranslation
+		var name, namespace sql.NullString
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&o.Game, &o.NativeHash, &name, &namespace, &o.Locale, &o.Status, &updatedAt); err != nil {
+			continue
+		}
+		o.Name = name.String
+		o.Namespace = namespace.String
+		if updatedAt.Valid {
+			o.UpdatedAt = &updatedAt.Time
+		}
+		result = append(result, o)
+	}
+	return result, nil
+}
+
+/**
+ * @brief 为尚无原文指纹的翻译补全指纹 (视为基于当前原文翻译)
+ */
+func backfillSourceHashes() {
+	rows, err := DB.Query(`SELECT nt.game, nt.native_hash, nt.locale, n.description_original, n.params
+		FROM native_translations nt
+		JOIN natives n ON n.game = nt.game AND n.hash = nt.native_hash
+		WHERE nt.source_hash IS NULL OR nt.source_hash = ''`)
+	if err != nil {
+		return
+	}
+
+	type pendingRow struct {
+		game, hash, locale, fingerprint string
+	}
+	var pending []pendingRow
+	for rows.Next() {
+		var game, hash, locale string
+		var desc sql.NullString
+		var paramsJSON []byte
+		if err := rows.Scan(&game, &hash, &locale, &desc, &paramsJSON); err != nil {
+			continue
+		}
+		pending = append(pending, pendingRow{game, hash, locale, SourceFingerprint(desc.String, paramsJSON)})
+	}
+	rows.Close()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return
+	}
+	for _, r := range pending {
+		if _, err := tx.Exec("UPDATE native_translations SET source_hash = ? WHERE game = ? AND native_hash = ? AND locale = ?", r.fingerprint, r.game, r.hash, r.locale); err != nil {
+			tx.Rollback()
+			log.Printf("Migration failed: %v", err)
+			return
+		}
+	}
+	if err := tx.Commit(); err == nil {
+		fmt.Printf("Migrated: Recorded source fingerprints for %d translations.\n", len(pending))
+	}
+}
@@ -0,0 +1,255 @@
+package core
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+const userColumns = "id, username, email, COALESCE(role, ''), COALESCE(disabled, 0), created_at"
+
+/**
+ * @brief 生成随机密码及其 bcrypt 哈希
+ * @return string 明文密码
+ * @return string 密码哈希
+ * @return error 生成错误
+ */
+func newPasswordHash() (string, string, error) {
+	password := GenerateRandomPassword(12)
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", "", err
+	}
+	return password, string(hash), nil
+}
+
+/**
+ * @brief 执行用户更新语句，用户不存在时返回 ErrUserNotFound
+ * @param username 用户名，作为语句的最后一个参数
+ * @param query SQL 语句
+ * @param args 用户名之前的查询参数
+ * @return error 执行错误
+ */
+func execUserUpdate(username, query string, args ...interface{}) error {
+	res, err := DB.Exec(query, append(args, username)...)
+	if err != nil {
+		return err
+	}
+	// MySQL 只统计实际变化的行，值未变化时需另行确认用户是否存在
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return userExists(username)
+	}
+	return nil
+}
+
+/**
+ * @brief 确认用户是否存在
+ * @param username 用户名
+ * @return error 不存在时返回 ErrUserNotFound
+ */
+func userExists(username string) error {
+	var exists bool
+	if err := DB.QueryRow("SELECT EXISTS(SELECT 1 FROM native_users WHERE username = ?)", username).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+/**
+ * @brief 创建用户
+ * @param username 用户名
+ * @param email 邮箱
+ * @param role 角色
+ * @return string 随机生成的密码
+ * @return error 创建错误
+ */
+func CreateUser(username, email, role string) (string, error) {
+	if !ValidRole(role) {
+		return "", fmt.Errorf("invalid role '%s'. Available roles: %s", role, strings.Join(Roles, ", "))
+	}
+	password, hash, err := newPasswordHash()
+	if err != nil {
+		return "", err
+	}
+	_, err = DB.Exec("INSERT INTO native_users (username, password_hash, email, role) VALUES (?, ?, ?, ?)", username, hash, email, role)
+	if err != nil {
+		return "", err
+	}
+	return password, nil
+}
+
+/**
+ * @brief 获取用户
+ * @param username 用户名
+ * @return *User 用户
+ * @return error 查询错误
+ */
+func GetUser(username string) (*User, error) {
+	var u User
+	err := DB.QueryRow("SELECT "+userColumns+" FROM native_users WHERE username = ?", username).
+		Scan(&u.ID, &u.Username, &u.Email, &u.Role, &u.Disabled, &u.CreatedAt)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	u.Avatar = GetGravatar(u.Email)
+	return &u, nil
+}
+
+/**
+ * @brief 列出所有用户
+ * @return []User 用户列表
+ * @return error 查询错误
+ */
+func ListUsers() ([]User, error) {
+	rows, err := DB.Query("SELECT " + userColumns + " FROM native_users ORDER BY id ASC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		var u User
+		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.Role, &u.Disabled, &u.CreatedAt); err != nil {
+			continue
+		}
+		u.Avatar = GetGravatar(u.Email)
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+/**
+ * @brief 重置用户密码
+ * @param username 用户名
+ * @return string 新密码
+ * @return error 重置错误
+ */
+func ResetUserPassword(username string) (string, error) {
+	password, hash, err := newPasswordHash()
+	if err != nil {
+		return "", err
+	}
+	if err := execUserUpdate(username, "UPDATE native_users SET password_hash = ? WHERE username = ?", hash); err != nil {
+		return "", err
+	}
+	return password, nil
+}
+
+/**
+ * @brief 修改用户角色
+ * @param username 用户名
+ * @param role 新角色
+ * @return error 修改错误
+ */
+func SetUserRole(username, role string) error {
+	if !ValidRole(role) {
+		return fmt.Errorf("invalid role '%s'. Available roles: %s", role, strings.Join(Roles, ", "))
+	}
+	return execUserUpdate(username, "UPDATE native_users SET role = ? WHERE username = ?", role)
+}
+
+/**
+ * @brief 修改用户邮箱
+ * @param username 用户名
+ * @param email 新邮箱
+ * @return error 修改错误
+ */
+func SetUserEmail(username, email string) error {
+	return execUserUpdate(username, "UPDATE native_users SET email = ? WHERE username = ?", email)
+}
+
+/**
+ * @brief 禁用或启用用户
+ * @param username 用户名
+ * @param disabled 是否禁用
+ * @return error 修改错误
+ */
+func SetUserDisabled(username string, disabled bool) error {
+	return execUserUpdate(username, "UPDATE native_users SET disabled = ? WHERE username = ?", disabled)
+}
+
+/**
+ * @brief 删除用户 (保留其历史贡献记录)
+ * @param username 用户名
+ * @return error 删除错误
+ */
+func DeleteUser(username string) error {
+	return execUserUpdate(username, "DELETE FROM native_users WHERE username = ?")
+}
+
+/**
+ * @brief 重命名用户，并同步更新其贡献记录中的署名
+ * @param oldName 原用户名
+ * @param newName 新用户名
+ * @return error 重命名错误
+ */
+func RenameUser(oldName, newName string) error {
+	// 新旧用户名相同时 MySQL 不计入受影响的行
+	if oldName == newName {
+		return userExists(oldName)
+	}
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec("UPDATE native_users SET username = ? WHERE username = ?", newName, oldName)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		tx.Rollback()
+		return ErrUserNotFound
+	}
+
+	updates := []string{
+		"UPDATE native_examples SET contributor = ? WHERE contributor = ?",
+		"UPDATE native_sources SET contributor = ? WHERE contributor = ?",
+		"UPDATE native_revisions SET author = ? WHERE author = ?",
+		"UPDATE native_proposals SET author = ? WHERE author = ?",
+		"UPDATE native_proposals SET reviewer = ? WHERE reviewer = ?",
+	}
+	for _, q := range updates {
+		if _, err := tx.Exec(q, newName, oldName); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+/**
+ * @brief 读取 token 所属用户的当前角色，用户 ID 与用户名需同时匹配，改名后以旧用户名新建的账号不会继承旧 token
+ * @param uid 用户 ID
+ * @param username 用户名
+ * @return string 当前角色
+ * @return bool 用户是否存在且未被禁用
+ */
+func ActiveUserRole(uid int, username string) (string, bool) {
+	var role sql.NullString
+	var disabled bool
+	if err := DB.QueryRow("SELECT role, COALESCE(disabled, 0) FROM native_users WHERE id = ? AND username = ?", uid, username).Scan(&role, &disabled); err != nil {
+		return "", false
+	}
+	return role.String, !disabled
+}
+
+/**
+ * @brief 统计可用的管理员数量
+ * @return int 管理员数量
+ */
+func CountActiveAdmins() int {
+	var count int
+	DB.QueryRow("SELECT COUNT(*) FROM native_users WHERE role = ? AND COALESCE(disabled, 0) = 0", RoleAdmin).Scan(&count)
+	return count
+}
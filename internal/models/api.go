@@ -18,17 +18,37 @@ type ExampleRequest struct {
 }
 
 type UpdateTranslationRequest struct {
+	Lang          string `json:"lang"`
+	Description   string `json:"description"`
 	DescriptionCn string `json:"description_cn"`
 }
 
+// Text 返回提交的翻译内容，description 优先，兼容旧字段 description_cn
+func (r UpdateTranslationRequest) Text() string {
+	if r.Description != "" {
+		return r.Description
+	}
+	return r.DescriptionCn
+}
+
 type NativeParam struct {
-	Name          string `json:"name"`
-	Type          string `json:"type"`
-	Description   string `json:"description"`
-	DescriptionCn string `json:"description_cn"`
+	Name                  string `json:"name"`
+	Type                  string `json:"type"`
+	Description           string `json:"description"`
+	DescriptionCn         string `json:"description_cn"`
+	DescriptionTranslated string `json:"description_translated,omitempty"`
+}
+
+// TranslatedDescription 返回提交的参数翻译，description_translated 优先，兼容旧字段 description_cn
+func (p NativeParam) TranslatedDescription() string {
+	if p.DescriptionTranslated != "" {
+		return p.DescriptionTranslated
+	}
+	return p.DescriptionCn
 }
 
 type UpdateParamsRequest struct {
+	Lang   string        `json:"lang"`
 	Params []NativeParam `json:"params"`
 }
 
@@ -39,6 +59,7 @@ type NativeListResponse struct {
 	NameSP           string          `json:"name_sp"`
 	Namespace        string          `json:"namespace"`
 	ApiSet           string          `json:"apiset"`
+	Game             string          `json:"game"`
 	ReturnType       string          `json:"return_type"`
 	Params           json.RawMessage `json:"params"`
 	Build            int             `json:"build_number"`
@@ -48,8 +69,12 @@ type NativeListResponse struct {
 
 type NativeDetailResponse struct {
 	NativeListResponse
-	DescriptionOriginal string  `json:"description_original"`
-	DescriptionCn       *string `json:"description_cn"`
+	DescriptionOriginal   string  `json:"description_original"`
+	DescriptionCn         *string `json:"description_cn"`
+	Lang                  string  `json:"lang,omitempty"`
+	DescriptionTranslated *string `json:"description_translated,omitempty"`
+	TranslationStatus     *int    `json:"translation_status,omitempty"`
+	TranslationOutdated   *bool   `json:"translation_outdated,omitempty"`
 }
 
 type SourceCodeResponse struct {
@@ -63,3 +88,82 @@ type ExampleResponse struct {
 	Language string `json:"language"`
 	Code     string `json:"code"`
 }
+
+type NativeSearchRequest struct {
+	Query      string `form:"q"`
+	Namespace  string `form:"namespace"`
+	ApiSet     string `form:"apiset"`
+	Game       string `form:"game"`
+	BuildMin   *int   `form:"build_min"`
+	BuildMax   *int   `form:"build_max"`
+	HasSource  *bool  `form:"has_source"`
+	HasExample *bool  `form:"has_example"`
+	Status     *int   `form:"status"`
+	Lang       string `form:"lang"`
+	Offset     int    `form:"offset"`
+	Limit      int    `form:"limit"`
+}
+
+type NativeSearchItem struct {
+	NativeListResponse
+	TranslationStatus int     `json:"translation_status"`
+	Snippet           string  `json:"snippet,omitempty"`
+	Score             float64 `json:"score"`
+}
+
+type NativeSearchResponse struct {
+	Total      int                `json:"total"`
+	Offset     int                `json:"offset"`
+	Limit      int                `json:"limit"`
+	NextOffset *int               `json:"next_offset"`
+	Items      []NativeSearchItem `json:"items"`
+}
+
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type RevisionDiffResponse struct {
+	Field string     `json:"field"`
+	From  int        `json:"from"`
+	To    int        `json:"to"`
+	Lines []DiffLine `json:"lines"`
+}
+
+type ReviewRequest struct {
+	Comment string `json:"comment"`
+}
+
+type CreateUserRequest struct {
+	Username string `json:"username" binding:"required"`
+	Email    string `json:"email" binding:"required"`
+	Role     string `json:"role"`
+}
+
+type UpdateEmailRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type GlossaryRequest struct {
+	Term        string `json:"term" binding:"required"`
+	Locale      string `json:"locale" binding:"required"`
+	Translation string `json:"translation" binding:"required"`
+	Note        string `json:"note"`
+}
+
+type TranslateJobRequest struct {
+	Locale           string   `json:"lang"`
+	Namespace        string   `json:"namespace"`
+	Hashes           []string `json:"hashes"`
+	Limit            int      `json:"limit"`
+	BatchTokens      *int     `json:"batch_tokens"`
+	UntranslatedOnly bool     `json:"untranslated_only"`
+	RetryFailed      bool     `json:"retry_failed"`
+	IncludeReviewed  bool     `json:"include_reviewed"`
+	NoMemory         bool     `json:"no_memory"`
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"nativedb/internal/core"
+	"nativedb/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * @brief 写入用户管理操作的错误响应
+ * @param c Gin 上下文
+ * @param err 错误
+ */
+func userError(c *gin.Context, err error) {
+	if err == core.ErrUserNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+/**
+ * @brief 检查操作是否会导致没有可用的管理员，或管理员锁定自己
+ * @param c Gin 上下文
+ * @param username 目标用户名
+ * @return bool 是否允许操作，不允许时已写入响应
+ */
+func guardAdminRemoval(c *gin.Context, username string) bool {
+	if username == c.GetString("username") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot perform this action on your own account"})
+		return false
+	}
+	user, err := core.GetUser(username)
+	if err != nil {
+		userError(c, err)
+		return false
+	}
+	if user.Role == core.RoleAdmin && !user.Disabled && core.CountActiveAdmins() <= 1 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot remove the last active admin"})
+		return false
+	}
+	return true
+}
+
+/**
+ * @brief 获取用户列表
+ * @param c Gin 上下文
+ */
+func AdminListUsers(c *gin.Context) {
+	users, err := core.ListUsers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+/**
+ * @brief 创建用户
+ * @param c Gin 上下文
+ */
+func AdminCreateUser(c *gin.Context) {
+	var req models.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Role == "" {
+		req.Role = core.RoleContributor
+	}
+	req.Role = strings.ToLower(req.Role)
+	if !core.ValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+	if _, err := core.GetUser(req.Username); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
+		return
+	}
+
+	password, err := core.CreateUser(req.Username, req.Email, req.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "created", "username": req.Username, "role": req.Role, "password": password})
+}
+
+/**
+ * @brief 禁用用户
+ * @param c Gin 上下文
+ */
+func AdminDisableUser(c *gin.Context) {
+	username := c.Param("username")
+	if !guardAdminRemoval(c, username) {
+		return
+	}
+	if err := core.SetUserDisabled(username, true); err != nil {
+		userError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "disabled"})
+}
+
+/**
+ * @brief 启用用户
+ * @param c Gin 上下文
+ */
+func AdminEnableUser(c *gin.Context) {
+	if err := core.SetUserDisabled(c.Param("username"), false); err != nil {
+		userError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "enabled"})
+}
+
+/**
+ * @brief 删除用户
+ * @param c Gin 上下文
+ */
+func AdminDeleteUser(c *gin.Context) {
+	username := c.Param("username")
+	if !guardAdminRemoval(c, username) {
+		return
+	}
+	if err := core.DeleteUser(username); err != nil {
+		userError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+/**
+ * @brief 重置用户密码
+ * @param c Gin 上下文
+ */
+func AdminResetPassword(c *gin.Context) {
+	username := c.Param("username")
+	password, err := core.ResetUserPassword(username)
+	if err != nil {
+		userError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "username": username, "password": password})
+}
+
+/**
+ * @brief 修改用户邮箱
+ * @param c Gin 上下文
+ */
+func AdminUpdateEmail(c *gin.Context) {
+	var req models.UpdateEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := core.SetUserEmail(c.Param("username"), req.Email); err != nil {
+		userError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "updated"})
+}
+
+/**
+ * @brief 修改用户角色
+ * @param c Gin 上下文
+ */
+func AdminUpdateRole(c *gin.Context) {
+	var req models.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	role := strings.ToLower(req.Role)
+	if !core.ValidRole(role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+	username := c.Param("username")
+	if role != core.RoleAdmin && !guardAdminRemoval(c, username) {
+		return
+	}
+	if err := core.SetUserRole(username, role); err != nil {
+		userError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "updated", "role": role})
+}
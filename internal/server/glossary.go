@@ -0,0 +1,137 @@
+package server
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"nativedb/internal/core"
+	"nativedb/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * @brief 绑定并校验术语请求
+ * @param c Gin 上下文
+ * @return *core.GlossaryEntry 术语
+ * @return bool 是否有效，无效时已写入响应
+ */
+func bindGlossaryEntry(c *gin.Context) (*core.GlossaryEntry, bool) {
+	var req models.GlossaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	locale, ok := core.NormalizeLocale(req.Locale)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid language code"})
+		return nil, false
+	}
+	return &core.GlossaryEntry{Term: req.Term, Locale: locale, Translation: req.Translation, Note: req.Note}, true
+}
+
+/**
+ * @brief 获取术语表
+ * @param c Gin 上下文
+ */
+func AdminListGlossary(c *gin.Context) {
+	locale, ok := queryLocale(c)
+	if !ok {
+		return
+	}
+	entries, err := core.ListGlossary(locale)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+/**
+ * @brief 新增术语 (同一语言下术语已存在时更新)
+ * @param c Gin 上下文
+ */
+func AdminCreateGlossary(c *gin.Context) {
+	e, ok := bindGlossaryEntry(c)
+	if !ok {
+		return
+	}
+	if err := core.SaveGlossaryEntry(e); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, e)
+}
+
+/**
+ * @brief 更新术语
+ * @param c Gin 上下文
+ */
+func AdminUpdateGlossary(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid glossary id"})
+		return
+	}
+	e, ok := bindGlossaryEntry(c)
+	if !ok {
+		return
+	}
+	e.ID = id
+	if err := core.UpdateGlossaryEntry(e); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Glossary entry not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	updated, err := core.GetGlossaryEntry(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+/**
+ * @brief 删除术语
+ * @param c Gin 上下文
+ */
+func AdminDeleteGlossary(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid glossary id"})
+		return
+	}
+	if err := core.DeleteGlossaryEntry(id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Glossary entry not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+/**
+ * @brief 检查指定语言的已有翻译是否违反术语表
+ * @param c Gin 上下文
+ */
+func AdminCheckGlossary(c *gin.Context) {
+	locale, ok := queryLocale(c)
+	if !ok {
+		return
+	}
+	if locale == "" {
+		locale = core.DefaultLocale
+	}
+	violations, err := core.CheckGlossary(locale)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"locale": locale, "total": len(violations), "items": violations})
+}
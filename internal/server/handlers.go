@@ -80,6 +80,32 @@ func cacheSet(key string, data interface{}) {
 	})
 }
 
+/**
+ * @brief 获取函数列表的缓存键
+ * @param game 游戏，为空时为所有游戏
+ * @return string 缓存键
+ */
+func nativesListCacheKey(game string) string {
+	if game == "" {
+		return CacheKeyNativesList
+	}
+	return CacheKeyNativesList + ":" + game
+}
+
+/**
+ * @brief 获取函数详情的缓存键，语言或游戏不同的详情分别缓存
+ * @param hash 函数哈希
+ * @param locale 语言代码，可为空
+ * @param game 游戏，可为空
+ * @return string 缓存键
+ */
+func nativeCacheKey(hash, locale, game string) string {
+	if locale == "" && game == "" {
+		return CacheKeyNativeBase + hash
+	}
+	return CacheKeyNativeBase + hash + "@" + locale + "/" + game
+}
+
 /**
  * @brief 清除缓存
  * @param nativeHash 可选，指定要清除的原生哈希
@@ -97,6 +123,36 @@ func clearCache(nativeHash string) {
 	for _, k := range keysToDelete {
 		core.LocalCache.Delete(k)
 	}
+
+	// 各游戏的列表缓存与各语言、游戏版本的详情缓存
+	clearCachePrefix(CacheKeyNativesList + ":")
+	if nativeHash != "" {
+		clearCachePrefix(CacheKeyNativeBase + nativeHash + "@")
+	}
+}
+
+/**
+ * @brief 清除指定前缀的所有缓存
+ * @param prefix 缓存键前缀
+ */
+func clearCachePrefix(prefix string) {
+	if core.Config.UseRedis && core.RDB != nil {
+		iter := core.RDB.Scan(core.Ctx, 0, prefix+"*", 100).Iterator()
+		var keys []string
+		for iter.Next(core.Ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if len(keys) > 0 {
+			core.RDB.Del(core.Ctx, keys...)
+		}
+	}
+
+	core.LocalCache.Range(func(key, _ interface{}) bool {
+		if k, ok := key.(string); ok && strings.HasPrefix(k, prefix) {
+			core.LocalCache.Delete(k)
+		}
+		return true
+	})
 }
 
 /**
@@ -111,8 +167,8 @@ func LoginHandler(c *gin.Context) {
 	}
 
 	var user core.User
-	err := core.DB.QueryRow("SELECT id, username, password_hash, email FROM native_users WHERE username = ?", req.Username).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Email)
-	if err != nil {
+	err := core.DB.QueryRow("SELECT id, username, password_hash, email, COALESCE(role, ''), COALESCE(disabled, 0) FROM native_users WHERE username = ?", req.Username).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Email, &user.Role, &user.Disabled)
+	if err != nil || user.Disabled {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
 		return
 	}
@@ -125,6 +181,7 @@ func LoginHandler(c *gin.Context) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"uid":      user.ID,
 		"username": user.Username,
+		"role":     user.Role,
 		"exp":      time.Now().Add(time.Hour * 72).Unix(),
 	})
 
@@ -139,6 +196,7 @@ func LoginHandler(c *gin.Context) {
 		"user": gin.H{
 			"username": user.Username,
 			"email":    user.Email,
+			"role":     user.Role,
 			"avatar":   core.GetGravatar(user.Email),
 		},
 	})
@@ -192,7 +250,7 @@ func ChangePasswordHandler(c *gin.Context) {
 func GetCurrentUser(c *gin.Context) {
 	username, _ := c.Get("username")
 	var user core.User
-	err := core.DB.QueryRow("SELECT id, username, email FROM native_users WHERE username = ?", username).Scan(&user.ID, &user.Username, &user.Email)
+	err := core.DB.QueryRow("SELECT id, username, email, COALESCE(role, '') FROM native_users WHERE username = ?", username).Scan(&user.ID, &user.Username, &user.Email, &user.Role)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
@@ -206,21 +264,31 @@ func GetCurrentUser(c *gin.Context) {
  * @param c Gin 上下文
  */
 func GetNativesList(c *gin.Context) {
-	if cacheGet(c, CacheKeyNativesList) {
+	game, ok := queryGame(c)
+	if !ok {
+		return
+	}
+	cacheKey := nativesListCacheKey(game)
+	if cacheGet(c, cacheKey) {
 		return
 	}
 
+	where, args := "", []interface{}{}
+	if game != "" {
+		where, args = "WHERE n.game = ?", append(args, game)
+	}
 	query := `
 		SELECT 
-			n.hash, n.jhash, n.name, n.name_sp, n.namespace, n.apiset, n.return_type, n.params, n.build_number,
+			n.hash, n.jhash, n.name, n.name_sp, n.namespace, n.apiset, n.game, n.return_type, n.params, n.build_number,
 			(ns.native_hash IS NOT NULL) AS source_available,
 			(ne.native_hash IS NOT NULL) AS example_available
 		FROM natives n
-		LEFT JOIN (SELECT DISTINCT native_hash FROM native_sources) ns ON n.hash = ns.native_hash
-		LEFT JOIN (SELECT DISTINCT native_hash FROM native_examples) ne ON n.hash = ne.native_hash
+		LEFT JOIN (SELECT DISTINCT game, native_hash FROM native_sources) ns ON n.game = ns.game AND n.hash = ns.native_hash
+		LEFT JOIN (SELECT DISTINCT game, native_hash FROM native_examples) ne ON n.game = ne.game AND n.hash = ne.native_hash
+		` + where + `
 		ORDER BY n.namespace ASC, n.name ASC;
 	`
-	rows, err := core.DB.Query(query)
+	rows, err := core.DB.Query(query, args...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -231,7 +299,7 @@ func GetNativesList(c *gin.Context) {
 	for rows.Next() {
 		var n models.NativeListResponse
 		var paramsJSON []byte
-		if err := rows.Scan(&n.Hash, &n.JHash, &n.Name, &n.NameSP, &n.Namespace, &n.ApiSet, &n.ReturnType, &paramsJSON, &n.Build, &n.SourceAvailable, &n.ExampleAvailable); err != nil {
+		if err := rows.Scan(&n.Hash, &n.JHash, &n.Name, &n.NameSP, &n.Namespace, &n.ApiSet, &n.Game, &n.ReturnType, &paramsJSON, &n.Build, &n.SourceAvailable, &n.ExampleAvailable); err != nil {
 			continue
 		}
 		n.Params = json.RawMessage(paramsJSON)
@@ -241,7 +309,7 @@ func GetNativesList(c *gin.Context) {
 		natives = append(natives, n)
 	}
 
-	cacheSet(CacheKeyNativesList, natives)
+	cacheSet(cacheKey, natives)
 	c.JSON(http.StatusOK, natives)
 }
 
@@ -251,14 +319,27 @@ func GetNativesList(c *gin.Context) {
  */
 func GetNativeDetail(c *gin.Context) {
 	hash := c.Param("hash")
-	if cacheGet(c, CacheKeyNativeBase+hash) {
+	locale := ""
+	if lang := c.Query("lang"); lang != "" {
+		var ok bool
+		if locale, ok = core.NormalizeLocale(lang); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid language code"})
+			return
+		}
+	}
+	game, ok := resolveNative(c)
+	if !ok {
+		return
+	}
+	cacheKey := nativeCacheKey(hash, locale, game)
+	if cacheGet(c, cacheKey) {
 		return
 	}
 
-	query := `SELECT hash, jhash, name, name_sp, namespace, apiset, return_type, params, build_number, description_original, description_cn FROM natives WHERE hash = ?`
+	query := `SELECT hash, jhash, name, name_sp, namespace, apiset, game, return_type, params, build_number, description_original, description_cn FROM natives WHERE game = ? AND hash = ?`
 	var n models.NativeDetailResponse
 	var paramsJSON []byte
-	err := core.DB.QueryRow(query, hash).Scan(&n.Hash, &n.JHash, &n.Name, &n.NameSP, &n.Namespace, &n.ApiSet, &n.ReturnType, &paramsJSON, &n.Build, &n.DescriptionOriginal, &n.DescriptionCn)
+	err := core.DB.QueryRow(query, game, hash).Scan(&n.Hash, &n.JHash, &n.Name, &n.NameSP, &n.Namespace, &n.ApiSet, &n.Game, &n.ReturnType, &paramsJSON, &n.Build, &n.DescriptionOriginal, &n.DescriptionCn)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Native not found"})
 		return
@@ -267,25 +348,62 @@ func GetNativeDetail(c *gin.Context) {
 	if len(paramsJSON) == 0 {
 		n.Params = json.RawMessage("[]")
 	}
+	if locale != "" {
+		if err := localizeNativeDetail(&n, locale); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
 	var hasSource bool
-	core.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM native_sources ns JOIN natives n ON n.hash = ? WHERE ns.native_hash = n.hash OR (n.jhash IS NOT NULL AND ns.native_hash = n.jhash))`, hash).Scan(&hasSource)
+	core.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM native_sources ns JOIN natives n ON n.game = ? AND n.hash = ? WHERE ns.game = n.game AND (ns.native_hash = n.hash OR (n.jhash IS NOT NULL AND ns.native_hash = n.jhash)))`, game, hash).Scan(&hasSource)
 
 	response := gin.H{"data": n, "source_available": hasSource}
-	cacheSet(CacheKeyNativeBase+hash, response)
+	cacheSet(cacheKey, response)
 	c.JSON(http.StatusOK, response)
 }
 
+/**
+ * @brief 为函数详情填充指定语言的翻译
+ * @param n 函数详情
+ * @param locale 语言代码
+ * @return error 查询错误
+ */
+func localizeNativeDetail(n *models.NativeDetailResponse, locale string) error {
+	t, err := core.GetTranslation(n.Game, n.Hash, locale)
+	if err != nil {
+		return err
+	}
+	n.Lang = locale
+	n.DescriptionTranslated = &t.Description
+	n.TranslationStatus = &t.Status
+	n.TranslationOutdated = &t.Outdated
+
+	var params []models.NativeParam
+	if err := json.Unmarshal(n.Params, &params); err != nil {
+		return nil
+	}
+	for i := range params {
+		params[i].DescriptionTranslated = t.Params[params[i].Name]
+	}
+	n.Params, _ = json.Marshal(params)
+	return nil
+}
+
 /**
  * @brief 获取函数源代码
  * @param c Gin 上下文
  */
 func GetNativeSource(c *gin.Context) {
 	hash := c.Param("hash")
+	game, ok := resolveNative(c)
+	if !ok {
+		return
+	}
 	query := `
 		SELECT ns.code_content, ns.code_lang, ns.source_type 
 		FROM native_sources ns 
-		JOIN natives n ON n.hash = ? 
-		WHERE ns.native_hash = n.hash OR (n.jhash IS NOT NULL AND ns.native_hash = n.jhash) 
+		JOIN natives n ON n.game = ? AND n.hash = ? 
+		WHERE ns.game = n.game AND (ns.native_hash = n.hash OR (n.jhash IS NOT NULL AND ns.native_hash = n.jhash)) 
 		ORDER BY CASE ns.source_type 
 			WHEN 'game_reversed' THEN 1 
 			WHEN 'cfx_open_source' THEN 2 
@@ -294,7 +412,7 @@ func GetNativeSource(c *gin.Context) {
 		LIMIT 1
 	`
 	var s models.SourceCodeResponse
-	err := core.DB.QueryRow(query, hash).Scan(&s.Content, &s.Language, &s.SourceType)
+	err := core.DB.QueryRow(query, game, hash).Scan(&s.Content, &s.Language, &s.SourceType)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Source code not found"})
 		return
@@ -308,7 +426,11 @@ func GetNativeSource(c *gin.Context) {
  */
 func GetNativeExamples(c *gin.Context) {
 	hash := c.Param("hash")
-	rows, err := core.DB.Query("SELECT id, language, code FROM native_examples WHERE native_hash = ?", hash)
+	game, ok := resolveNative(c)
+	if !ok {
+		return
+	}
+	rows, err := core.DB.Query("SELECT id, language, code FROM native_examples WHERE game = ? AND native_hash = ?", game, hash)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -324,26 +446,22 @@ func GetNativeExamples(c *gin.Context) {
 }
 
 /**
- * @brief 添加或更新函数示例代码
- * @param c Gin 上下文
+ * @brief 保存示例代码 (存在则更新，否则插入) 并记录修订
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param language 示例语言
+ * @param code 示例代码
+ * @param username 修改者
+ * @return error 保存错误
  */
-func AddOrUpdateExample(c *gin.Context) {
-	hash := c.Param("hash")
-	var req models.ExampleRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-	req.Language = strings.ToLower(req.Language)
-	username := c.GetString("username")
-
+func saveExample(game, hash, language, code, username string) error {
 	var existingId int
-	err := core.DB.QueryRow("SELECT id FROM native_examples WHERE native_hash = ? AND language = ?", hash, req.Language).Scan(&existingId)
+	var oldCode string
+	err := core.DB.QueryRow("SELECT id, code FROM native_examples WHERE game = ? AND native_hash = ? AND language = ?", game, hash, language).Scan(&existingId, &oldCode)
 	if err == sql.ErrNoRows {
-		_, err := core.DB.Exec("INSERT INTO native_examples (native_hash, language, code, contributor) VALUES (?, ?, ?, ?)", hash, req.Language, req.Code, username)
+		_, err := core.DB.Exec("INSERT INTO native_examples (game, native_hash, language, code, contributor) VALUES (?, ?, ?, ?, ?)", game, hash, language, code, username)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
+			return err
 		}
 	} else {
 		var updateSQL string
@@ -352,12 +470,60 @@ func AddOrUpdateExample(c *gin.Context) {
 		} else {
 			updateSQL = "UPDATE native_examples SET code = ?, updated_at = NOW(), contributor = ? WHERE id = ?"
 		}
-		_, err := core.DB.Exec(updateSQL, req.Code, username, existingId)
+		_, err := core.DB.Exec(updateSQL, code, username, existingId)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
+			return err
 		}
 	}
+	return core.RecordRevision(game, hash, core.ExampleRevisionField(language), oldCode, code, username)
+}
+
+/**
+ * @brief 删除示例代码并记录修订
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param language 示例语言
+ * @param username 修改者
+ * @return bool 是否存在并已删除
+ * @return error 删除错误
+ */
+func removeExample(game, hash, language, username string) (bool, error) {
+	var oldCode string
+	err := core.DB.QueryRow("SELECT code FROM native_examples WHERE game = ? AND native_hash = ? AND language = ?", game, hash, language).Scan(&oldCode)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if _, err := core.DB.Exec("DELETE FROM native_examples WHERE game = ? AND native_hash = ? AND language = ?", game, hash, language); err != nil {
+		return false, err
+	}
+	return true, core.RecordRevision(game, hash, core.ExampleRevisionField(language), oldCode, "", username)
+}
+
+/**
+ * @brief 添加或更新函数示例代码
+ * @param c Gin 上下文
+ */
+func AddOrUpdateExample(c *gin.Context) {
+	hash := c.Param("hash")
+	game, ok := resolveNative(c)
+	if !ok {
+		return
+	}
+	var req models.ExampleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Language = strings.ToLower(req.Language)
+	username := c.GetString("username")
+
+	if err := saveExample(game, hash, req.Language, req.Code, username); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 	clearCache(hash)
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
@@ -368,6 +534,10 @@ func AddOrUpdateExample(c *gin.Context) {
  */
 func DeleteExample(c *gin.Context) {
 	hash := c.Param("hash")
+	game, ok := resolveNative(c)
+	if !ok {
+		return
+	}
 	lang := c.Query("language")
 	if lang == "" {
 		var req struct {
@@ -381,12 +551,12 @@ func DeleteExample(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Language required"})
 		return
 	}
-	res, err := core.DB.Exec("DELETE FROM native_examples WHERE native_hash = ? AND language = ?", hash, strings.ToLower(lang))
+	deleted, err := removeExample(game, hash, strings.ToLower(lang), c.GetString("username"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	if rows, _ := res.RowsAffected(); rows == 0 {
+	if !deleted {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Example not found"})
 		return
 	}
@@ -394,6 +564,94 @@ func DeleteExample(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
 }
 
+/**
+ * @brief 获取请求的翻译语言
+ * @param c Gin 上下文
+ * @param bodyLang 请求体中的语言，优先于查询参数
+ * @return string 规范化后的语言代码，未指定时为默认语言
+ * @return bool 是否有效
+ */
+func requestLocale(c *gin.Context, bodyLang string) (string, bool) {
+	lang := bodyLang
+	if lang == "" {
+		lang = c.Query("lang")
+	}
+	if lang == "" {
+		return core.DefaultLocale, true
+	}
+	return core.NormalizeLocale(lang)
+}
+
+/**
+ * @brief 写入函数描述翻译并记录修订
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param locale 语言代码
+ * @param text 翻译内容
+ * @param username 修改者
+ * @return error 写入错误
+ */
+func applyTranslation(game, hash, locale, text, username string) error {
+	t, err := core.GetTranslation(game, hash, locale)
+	if err != nil {
+		return err
+	}
+	oldText := t.Description
+	t.Description = text
+	t.Status = core.TranslationStatusReviewed
+	if err := core.SaveTranslation(t); err != nil {
+		return err
+	}
+	if err := core.RecordRevision(game, hash, core.DescriptionField(locale), oldText, text, username); err != nil {
+		return err
+	}
+	return core.SyncSearchIndex(game, hash)
+}
+
+/**
+ * @brief 写入参数翻译并记录修订
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param locale 语言代码
+ * @param params 参数翻译 (按参数名匹配)
+ * @param username 修改者
+ * @return int 更新的参数数量
+ * @return error 写入错误
+ */
+func applyParamsTranslation(game, hash, locale string, params []models.NativeParam, username string) (int, error) {
+	var currentParamsJSON []byte
+	if err := core.DB.QueryRow("SELECT params FROM natives WHERE game = ? AND hash = ?", game, hash).Scan(&currentParamsJSON); err != nil {
+		return 0, err
+	}
+	var currentParams []models.NativeParam
+	json.Unmarshal(currentParamsJSON, &currentParams)
+
+	t, err := core.GetTranslation(game, hash, locale)
+	if err != nil {
+		return 0, err
+	}
+	oldJSON, _ := json.Marshal(t.Params)
+
+	updatedCount := 0
+	for _, cur := range currentParams {
+		for _, newP := range params {
+			if cur.Name == newP.Name {
+				t.Params[cur.Name] = newP.TranslatedDescription()
+				updatedCount++
+				break
+			}
+		}
+	}
+	if err := core.SaveTranslation(t); err != nil {
+		return 0, err
+	}
+	newJSON, _ := json.Marshal(t.Params)
+	if err := core.RecordRevision(game, hash, core.ParamsField(locale), string(oldJSON), string(newJSON), username); err != nil {
+		return 0, err
+	}
+	return updatedCount, core.SyncSearchIndex(game, hash)
+}
+
 /**
  * @brief 更新函数翻译
  * @param c Gin 上下文
@@ -405,11 +663,37 @@ func UpdateNativeTranslation(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	_, err := core.DB.Exec("UPDATE natives SET description_cn = ?, translation_status = 2 WHERE hash = ?", req.DescriptionCn, hash)
+	locale, ok := requestLocale(c, req.Lang)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid language code"})
+		return
+	}
+	game, ok := resolveNative(c)
+	if !ok {
+		return
+	}
+	current, err := core.GetTranslation(game, hash, locale)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	text := req.Text()
+
+	username := c.GetString("username")
+	if !canPublish(c) {
+		id, err := core.CreateProposal(game, hash, core.DescriptionField(locale), current.Description, text, username)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{"status": "pending", "proposal_id": id})
+		return
+	}
+
+	if err := applyTranslation(game, hash, locale, text, username); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 	clearCache(hash)
 	c.JSON(http.StatusOK, gin.H{"status": "updated"})
 }
@@ -425,22 +709,169 @@ func UpdateNativeParams(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	var currentParamsJSON []byte
-	core.DB.QueryRow("SELECT params FROM natives WHERE hash = ?", hash).Scan(&currentParamsJSON)
-	var currentParams []models.NativeParam
-	json.Unmarshal(currentParamsJSON, &currentParams)
-	updatedCount := 0
-	for i := range currentParams {
-		for _, newP := range req.Params {
-			if currentParams[i].Name == newP.Name {
-				currentParams[i].DescriptionCn = newP.DescriptionCn
-				updatedCount++
-				break
-			}
+	locale, ok := requestLocale(c, req.Lang)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid language code"})
+		return
+	}
+	game, ok := resolveNative(c)
+	if !ok {
+		return
+	}
+
+	username := c.GetString("username")
+	if !canPublish(c) {
+		current, err := core.GetTranslation(game, hash, locale)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		base, _ := json.Marshal(current.Params)
+		value, _ := json.Marshal(req.Params)
+		id, err := core.CreateProposal(game, hash, core.ParamsField(locale), string(base), string(value), username)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
+		c.JSON(http.StatusAccepted, gin.H{"status": "pending", "proposal_id": id})
+		return
+	}
+
+	updatedCount, err := applyParamsTranslation(game, hash, locale, req.Params, username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
-	finalJSON, _ := json.Marshal(currentParams)
-	core.DB.Exec("UPDATE natives SET params = ? WHERE hash = ?", finalJSON, hash)
 	clearCache(hash)
 	c.JSON(http.StatusOK, gin.H{"status": "updated", "updated_count": updatedCount})
 }
+
+/**
+ * @brief 获取函数的所有语言翻译
+ * @param c Gin 上下文
+ */
+func GetNativeTranslations(c *gin.Context) {
+	game, ok := resolveNative(c)
+	if !ok {
+		return
+	}
+	translations, err := core.ListTranslations(game, c.Param("hash"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, translations)
+}
+
+/**
+ * @brief 解析查询参数中的可选语言
+ * @param c Gin 上下文
+ * @return string 语言代码，未提供时为空
+ * @return bool 是否有效，无效时已写入响应
+ */
+func queryLocale(c *gin.Context) (string, bool) {
+	lang := c.Query("lang")
+	if lang == "" {
+		return "", true
+	}
+	locale, ok := core.NormalizeLocale(lang)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid language code"})
+		return "", false
+	}
+	return locale, true
+}
+
+/**
+ * @brief 解析查询参数中的可选游戏
+ * @param c Gin 上下文
+ * @return string 游戏代码，未提供时为空
+ * @return bool 是否有效，无效时已写入响应
+ */
+func queryGame(c *gin.Context) (string, bool) {
+	value := c.Query("game")
+	if value == "" {
+		return "", true
+	}
+	game, ok := core.NormalizeGame(value)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown game"})
+		return "", false
+	}
+	return game, true
+}
+
+/**
+ * @brief 确定路由中的函数所属的游戏，哈希只存在于一个游戏时 game 查询参数可省略
+ * @param c Gin 上下文
+ * @return string 游戏代码
+ * @return bool 是否继续处理，否则已写入响应 (游戏无效 400，函数不存在 404，哈希存在于多个游戏而未指定游戏 409 并附带游戏列表)
+ */
+func resolveNative(c *gin.Context) (string, bool) {
+	game, ok := queryGame(c)
+	if !ok {
+		return "", false
+	}
+	game, err := core.ResolveNativeGame(c.Param("hash"), game)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Native not found"})
+		return "", false
+	}
+	if err == core.ErrNativeAmbiguous {
+		games, _ := core.NativeGames(c.Param("hash"))
+		c.JSON(http.StatusConflict, gin.H{"error": "Native hash exists in more than one game, specify ?game=", "games": games})
+		return "", false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return "", false
+	}
+	return game, true
+}
+
+/**
+ * @brief 获取原文已变化、需要重新翻译的过期翻译列表
+ * @param c Gin 上下文
+ */
+func GetOutdatedTranslations(c *gin.Context) {
+	locale, ok := queryLocale(c)
+	if !ok {
+		return
+	}
+	game, ok := queryGame(c)
+	if !ok {
+		return
+	}
+	outdated, err := core.ListOutdatedTranslations(locale, game)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"total": len(outdated), "items": outdated})
+}
+
+/**
+ * @brief 获取已有翻译的语言列表
+ * @param c Gin 上下文
+ */
+func GetLocales(c *gin.Context) {
+	locales, err := core.ListLocales()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"default": core.DefaultLocale, "locales": locales})
+}
+
+/**
+ * @brief 获取游戏列表及各游戏的函数数量
+ * @param c Gin 上下文
+ */
+func GetGames(c *gin.Context) {
+	games, err := core.ListGames()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"default": core.DefaultGame, "games": games})
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"nativedb/internal/core"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * @brief 获取最近的函数数据导入记录
+ * @param c Gin 上下文
+ */
+func GetImportRuns(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	game, ok := queryGame(c)
+	if !ok {
+		return
+	}
+	runs, err := core.ListImportRuns(limit, game)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+/**
+ * @brief 获取单次导入的变更报告 (新增、删除、改名、签名变化与描述变化的函数)
+ * @param c Gin 上下文
+ */
+func GetImportReport(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import id"})
+		return
+	}
+	report, err := core.GetImportReport(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Import run not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -43,10 +43,35 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		if claims, ok := token.Claims.(jwt.MapClaims); ok {
+			// 角色每次从数据库读取，降级立即生效；已删除、禁用或改名的用户的 token 立即失效
+			username, _ := claims["username"].(string)
+			uid, _ := claims["uid"].(float64)
+			role, active := core.ActiveUserRole(int(uid), username)
+			if !active {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Account is disabled or no longer exists"})
+				return
+			}
+
 			c.Set("username", claims["username"])
 			c.Set("uid", claims["uid"])
+			c.Set("role", role)
 		}
 
 		c.Next()
 	}
 }
+
+/**
+ * @brief 角色权限中间件，需在 AuthMiddleware 之后使用
+ * @param required 需要的最低角色
+ * @return gin.HandlerFunc 权限检查处理函数
+ */
+func RequireRole(required string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !core.HasRole(c.GetString("role"), required) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("Permission denied: '%s' role required", required)})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"nativedb/internal/core"
+	"nativedb/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * @brief 判断当前用户的修改是否可以直接发布 (无需审核)
+ * @param c Gin 上下文
+ * @return bool 是否为翻译者及以上角色
+ */
+func canPublish(c *gin.Context) bool {
+	return core.HasRole(c.GetString("role"), core.RoleTranslator)
+}
+
+/**
+ * @brief 获取审核队列
+ * @param c Gin 上下文
+ */
+func GetReviewQueue(c *gin.Context) {
+	status := c.DefaultQuery("status", core.ProposalPending)
+	if status == "all" {
+		status = ""
+	}
+	game, ok := queryGame(c)
+	if !ok {
+		return
+	}
+	proposals, err := core.ListProposals(status, game, c.Query("hash"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, proposals)
+}
+
+/**
+ * @brief 获取函数的修改提案
+ * @param c Gin 上下文
+ */
+func GetNativeProposals(c *gin.Context) {
+	game, ok := resolveNative(c)
+	if !ok {
+		return
+	}
+	proposals, err := core.ListProposals(c.Query("status"), game, c.Param("hash"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, proposals)
+}
+
+/**
+ * @brief 读取待审核的提案
+ * @param c Gin 上下文
+ * @return *core.Proposal 提案，失败时已写入响应并返回 nil
+ * @return string 审核意见
+ */
+func loadPendingProposal(c *gin.Context) (*core.Proposal, string) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid proposal id"})
+		return nil, ""
+	}
+	var req models.ReviewRequest
+	c.ShouldBindJSON(&req)
+
+	p, err := core.GetProposal(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Proposal not found"})
+		return nil, ""
+	}
+	if p.Status != core.ProposalPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Proposal already reviewed"})
+		return nil, ""
+	}
+	return p, req.Comment
+}
+
+/**
+ * @brief 通过提案并发布修改
+ * @param c Gin 上下文
+ */
+func ApproveProposal(c *gin.Context) {
+	pending, comment := loadPendingProposal(c)
+	if pending == nil {
+		return
+	}
+	field, locale := core.ParseTranslationField(pending.Field)
+	if field != core.RevisionFieldDescription && field != core.RevisionFieldParams {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported proposal field"})
+		return
+	}
+
+	// 先认领提案，并发审核时只有一方会发布修改
+	p, err := core.ClaimProposal(pending.ID, c.GetString("username"), comment)
+	switch {
+	case err == core.ErrProposalReviewed:
+		c.JSON(http.StatusConflict, gin.H{"error": "Proposal already reviewed"})
+		return
+	case err == core.ErrProposalStale:
+		c.JSON(http.StatusConflict, gin.H{"error": "Content changed since the proposal was made"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch field {
+	case core.RevisionFieldDescription:
+		err = applyTranslation(p.Game, p.NativeHash, locale, p.Value, p.Author)
+	case core.RevisionFieldParams:
+		var params []models.NativeParam
+		if err = json.Unmarshal([]byte(p.Value), &params); err == nil {
+			_, err = applyParamsTranslation(p.Game, p.NativeHash, locale, params, p.Author)
+		}
+	}
+	if err != nil {
+		core.ReleaseProposal(p.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	clearCache(p.NativeHash)
+	c.JSON(http.StatusOK, gin.H{"status": core.ProposalApproved})
+}
+
+/**
+ * @brief 驳回提案
+ * @param c Gin 上下文
+ */
+func RejectProposal(c *gin.Context) {
+	p, comment := loadPendingProposal(c)
+	if p == nil {
+		return
+	}
+	if err := core.ReviewProposal(p.ID, core.ProposalRejected, c.GetString("username"), comment); err == core.ErrProposalReviewed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Proposal already reviewed"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": core.ProposalRejected})
+}
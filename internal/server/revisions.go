@@ -0,0 +1,273 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"nativedb/internal/core"
+	"nativedb/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * @brief 获取函数的修订历史
+ * @param c Gin 上下文
+ */
+func GetNativeRevisions(c *gin.Context) {
+	game, ok := resolveNative(c)
+	if !ok {
+		return
+	}
+	revisions, err := core.ListRevisions(game, c.Param("hash"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, revisions)
+}
+
+/**
+ * @brief 对比两个修订版本
+ * @param c Gin 上下文
+ */
+func DiffNativeRevisions(c *gin.Context) {
+	hash := c.Param("hash")
+	game, ok := resolveNative(c)
+	if !ok {
+		return
+	}
+	fromID, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'from' revision"})
+		return
+	}
+	from, err := core.GetRevision(game, hash, fromID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		return
+	}
+
+	// 未指定 to 时对比该修订自身的修改前后内容
+	if c.Query("to") == "" {
+		c.JSON(http.StatusOK, models.RevisionDiffResponse{
+			Field: from.Field,
+			From:  from.ID,
+			To:    from.ID,
+			Lines: diffLines(from.OldValue, from.NewValue),
+		})
+		return
+	}
+
+	toID, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'to' revision"})
+		return
+	}
+	to, err := core.GetRevision(game, hash, toID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		return
+	}
+	if from.Field != to.Field {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Revisions belong to different fields"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RevisionDiffResponse{
+		Field: from.Field,
+		From:  from.ID,
+		To:    to.ID,
+		Lines: diffLines(from.NewValue, to.NewValue),
+	})
+}
+
+/**
+ * @brief 回滚到指定修订版本
+ * @param c Gin 上下文
+ */
+func RollbackNativeRevision(c *gin.Context) {
+	hash := c.Param("hash")
+	game, ok := resolveNative(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision id"})
+		return
+	}
+	rev, err := core.GetRevision(game, hash, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		return
+	}
+
+	// state=before 表示撤销该修订，恢复到修改之前的内容
+	target := rev.NewValue
+	if c.Query("state") == "before" {
+		target = rev.OldValue
+	}
+
+	if err := applyRevisionValue(game, hash, rev.Field, target, c.GetString("username")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	clearCache(hash)
+	c.JSON(http.StatusOK, gin.H{"status": "rolled_back", "field": rev.Field})
+}
+
+/**
+ * @brief 将字段写回指定内容并记录新的修订
+ * @param game 游戏
+ * @param hash 函数哈希
+ * @param field 字段名
+ * @param value 目标内容
+ * @param username 操作者
+ * @return error 写入错误
+ */
+func applyRevisionValue(game, hash, field, value, username string) error {
+	if strings.HasPrefix(field, core.RevisionFieldExamplePrefix) {
+		language := strings.TrimPrefix(field, core.RevisionFieldExamplePrefix)
+		if value == "" {
+			_, err := removeExample(game, hash, language, username)
+			return err
+		}
+		return saveExample(game, hash, language, value, username)
+	}
+
+	base, locale := core.ParseTranslationField(field)
+	switch base {
+	case core.RevisionFieldDescription:
+		return applyTranslation(game, hash, locale, value, username)
+	case core.RevisionFieldParams:
+		params, err := core.ParseParamsRevision(value)
+		if err != nil {
+			return err
+		}
+		t, err := core.GetTranslation(game, hash, locale)
+		if err != nil {
+			return err
+		}
+		oldJSON, _ := json.Marshal(t.Params)
+		t.Params = params
+		if err := core.SaveTranslation(t); err != nil {
+			return err
+		}
+		newJSON, _ := json.Marshal(t.Params)
+		if err := core.RecordRevision(game, hash, field, string(oldJSON), string(newJSON), username); err != nil {
+			return err
+		}
+		return core.SyncSearchIndex(game, hash)
+	default:
+		return fmt.Errorf("unsupported revision field: %s", field)
+	}
+}
+
+// maxDiffCells 为最长公共子序列矩阵的最大单元数，超出时不再逐行对齐，避免大文本占用过多内存
+const maxDiffCells = 1 << 20
+
+/**
+ * @brief 按行计算两个文本的差异 (基于最长公共子序列)，相同的首尾行不参与计算
+ * @param a 旧文本
+ * @param b 新文本
+ * @return []models.DiffLine 差异行
+ */
+func diffLines(a, b string) []models.DiffLine {
+	x := splitLines(a)
+	y := splitLines(b)
+
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]models.DiffLine, 0, len(x)+len(y)-prefix-suffix)
+	for _, line := range x[:prefix] {
+		lines = append(lines, models.DiffLine{Op: " ", Text: line})
+	}
+	lines = append(lines, diffMiddle(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])...)
+	for _, line := range x[len(x)-suffix:] {
+		lines = append(lines, models.DiffLine{Op: " ", Text: line})
+	}
+	return lines
+}
+
+/**
+ * @brief 计算去掉相同首尾行后的差异，矩阵过大时整段输出为删除与新增
+ * @param x 旧文本的行
+ * @param y 新文本的行
+ * @return []models.DiffLine 差异行
+ */
+func diffMiddle(x, y []string) []models.DiffLine {
+	n, m := len(x), len(y)
+	if (n+1)*(m+1) > maxDiffCells {
+		lines := make([]models.DiffLine, 0, n+m)
+		for _, line := range x {
+			lines = append(lines, models.DiffLine{Op: "-", Text: line})
+		}
+		for _, line := range y {
+			lines = append(lines, models.DiffLine{Op: "+", Text: line})
+		}
+		return lines
+	}
+
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := make([]models.DiffLine, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case x[i] == y[j]:
+			lines = append(lines, models.DiffLine{Op: " ", Text: x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, models.DiffLine{Op: "-", Text: x[i]})
+			i++
+		default:
+			lines = append(lines, models.DiffLine{Op: "+", Text: y[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		lines = append(lines, models.DiffLine{Op: "-", Text: x[i]})
+	}
+	for ; j < m; j++ {
+		lines = append(lines, models.DiffLine{Op: "+", Text: y[j]})
+	}
+	return lines
+}
+
+/**
+ * @brief 将文本拆分为行，空文本返回空切片
+ * @param s 文本
+ * @return []string 行
+ */
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
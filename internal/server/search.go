@@ -0,0 +1,210 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"nativedb/internal/core"
+	"nativedb/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	SearchDefaultLimit = 50
+	SearchMaxLimit     = 500
+)
+
+/**
+ * @brief 根据搜索请求构建 WHERE 子句
+ * @param req 搜索请求
+ * @param fulltext 是否同时匹配全文索引 (需 JOIN 别名 fs)
+ * @return string WHERE 子句 (不含 WHERE 关键字)
+ * @return []interface{} 查询参数
+ */
+func buildSearchFilter(req *models.NativeSearchRequest, fulltext bool) (string, []interface{}) {
+	conds := []string{"1=1"}
+	args := []interface{}{}
+
+	if q := strings.TrimSpace(req.Query); q != "" {
+		like := "%" + strings.ToLower(q) + "%"
+		cond := "LOWER(n.name) LIKE ? OR LOWER(n.name_sp) LIKE ? OR LOWER(n.hash) LIKE ? OR LOWER(n.jhash) LIKE ?"
+		if fulltext {
+			cond += " OR fs.hash IS NOT NULL"
+		}
+		conds = append(conds, "("+cond+")")
+		args = append(args, like, like, like, like)
+	}
+	if req.Namespace != "" {
+		conds = append(conds, "n.namespace = ?")
+		args = append(args, strings.ToUpper(req.Namespace))
+	}
+	if req.ApiSet != "" {
+		conds = append(conds, "n.apiset = ?")
+		args = append(args, strings.ToLower(req.ApiSet))
+	}
+	if req.Game != "" {
+		conds = append(conds, "n.game = ?")
+		args = append(args, req.Game)
+	}
+	if req.BuildMin != nil {
+		conds = append(conds, "n.build_number >= ?")
+		args = append(args, *req.BuildMin)
+	}
+	if req.BuildMax != nil {
+		conds = append(conds, "n.build_number <= ?")
+		args = append(args, *req.BuildMax)
+	}
+	if req.HasSource != nil {
+		if *req.HasSource {
+			conds = append(conds, "ns.native_hash IS NOT NULL")
+		} else {
+			conds = append(conds, "ns.native_hash IS NULL")
+		}
+	}
+	if req.HasExample != nil {
+		if *req.HasExample {
+			conds = append(conds, "ne.native_hash IS NOT NULL")
+		} else {
+			conds = append(conds, "ne.native_hash IS NULL")
+		}
+	}
+	if req.Status != nil {
+		// 指定语言时按该语言的翻译状态过滤 (需 JOIN 别名 nt)
+		if req.Lang != "" {
+			conds = append(conds, "COALESCE(nt.status, 0) = ?")
+		} else {
+			conds = append(conds, "n.translation_status = ?")
+		}
+		args = append(args, *req.Status)
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+/**
+ * @brief 搜索函数列表 (服务端过滤与分页)
+ * @param c Gin 上下文
+ */
+func SearchNatives(c *gin.Context) {
+	var req models.NativeSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+	if req.Limit <= 0 {
+		req.Limit = SearchDefaultLimit
+	}
+	if req.Limit > SearchMaxLimit {
+		req.Limit = SearchMaxLimit
+	}
+	if req.Lang != "" {
+		locale, ok := core.NormalizeLocale(req.Lang)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid language code"})
+			return
+		}
+		req.Lang = locale
+	}
+	if req.Game != "" {
+		game, ok := core.NormalizeGame(req.Game)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown game"})
+			return
+		}
+		req.Game = game
+	}
+
+	ftsQuery, args, fulltext := "", []interface{}{}, false
+	if strings.TrimSpace(req.Query) != "" {
+		ftsQuery, args, fulltext = core.FulltextSubquery(req.Query)
+	}
+
+	from := `
+		FROM natives n
+		LEFT JOIN (SELECT DISTINCT game, native_hash FROM native_sources) ns ON n.game = ns.game AND n.hash = ns.native_hash
+		LEFT JOIN (SELECT DISTINCT game, native_hash FROM native_examples) ne ON n.game = ne.game AND n.hash = ne.native_hash`
+	selectRank := "NULL, 0"
+	orderBy := "n.namespace ASC, n.name ASC, n.hash ASC"
+	var orderArgs []interface{}
+	if fulltext {
+		from += `
+		LEFT JOIN (` + ftsQuery + `) fs ON fs.game = n.game AND fs.hash = n.hash`
+		selectRank = "fs.snippet, COALESCE(fs.score, 0)"
+		orderBy = "CASE WHEN LOWER(n.name) = ? THEN 0 ELSE 1 END, COALESCE(fs.score, 0) ASC, " + orderBy
+		orderArgs = append(orderArgs, strings.ToLower(strings.TrimSpace(req.Query)))
+	}
+	statusColumn := "n.translation_status"
+	if req.Lang != "" {
+		from += `
+		LEFT JOIN native_translations nt ON nt.game = n.game AND nt.native_hash = n.hash AND nt.locale = ?`
+		args = append(args, req.Lang)
+		statusColumn = "COALESCE(nt.status, 0)"
+	}
+	where, whereArgs := buildSearchFilter(&req, fulltext)
+	from += `
+		WHERE ` + where
+	args = append(args, whereArgs...)
+
+	var total int
+	if err := core.DB.QueryRow("SELECT COUNT(*) "+from, args...).Scan(&total); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := `
+		SELECT
+			n.hash, n.jhash, n.name, n.name_sp, n.namespace, n.apiset, n.game, n.return_type, n.params, n.build_number,
+			(ns.native_hash IS NOT NULL) AS source_available,
+			(ne.native_hash IS NOT NULL) AS example_available,
+			` + statusColumn + `, ` + selectRank + `
+		` + from + `
+		ORDER BY ` + orderBy + `
+		LIMIT ? OFFSET ?`
+	queryArgs := append(append(args, orderArgs...), req.Limit, req.Offset)
+	rows, err := core.DB.Query(query, queryArgs...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	terms := core.SearchTerms(req.Query)
+	items := make([]models.NativeSearchItem, 0, req.Limit)
+	for rows.Next() {
+		var n models.NativeSearchItem
+		var paramsJSON []byte
+		var snippet sql.NullString
+		if err := rows.Scan(&n.Hash, &n.JHash, &n.Name, &n.NameSP, &n.Namespace, &n.ApiSet, &n.Game, &n.ReturnType, &paramsJSON, &n.Build, &n.SourceAvailable, &n.ExampleAvailable, &n.TranslationStatus, &snippet, &n.Score); err != nil {
+			continue
+		}
+		if snippet.Valid && snippet.String != "" {
+			if core.Config.DbType == "sqlite" {
+				n.Snippet = core.HighlightSnippet(snippet.String)
+			} else {
+				n.Snippet = core.MakeSnippet(snippet.String, terms)
+			}
+		}
+		n.Params = json.RawMessage(paramsJSON)
+		if len(paramsJSON) == 0 {
+			n.Params = json.RawMessage("[]")
+		}
+		items = append(items, n)
+	}
+
+	resp := models.NativeSearchResponse{
+		Total:  total,
+		Offset: req.Offset,
+		Limit:  req.Limit,
+		Items:  items,
+	}
+	if next := req.Offset + len(items); next < total {
+		resp.NextOffset = &next
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -23,7 +23,8 @@ import (
 func Start(config *core.AppConfig) error {
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.Default()
-	r.Use(gzip.Gzip(gzip.DefaultCompression))
+	// SSE 进度推送需要逐条刷新，不经过 gzip 缓冲
+	r.Use(gzip.Gzip(gzip.DefaultCompression, gzip.WithExcludedPathsRegexs([]string{`/events$`})))
 
 	// CORS 配置
 	r.Use(cors.New(cors.Config{
@@ -38,6 +39,9 @@ func Start(config *core.AppConfig) error {
 	// 注册路由
 	registerRoutes(r)
 
+	// 上游数据定时同步
+	startSyncScheduler(config)
+
 	// 前端静态文件服务
 	if config.FrontendPath != "" {
 		if _, err := os.Stat(config.FrontendPath); !os.IsNotExist(err) {
@@ -71,9 +75,16 @@ func registerRoutes(r *gin.Engine) {
 	{
 		// 公开接口
 		api.GET("/natives", GetNativesList)
+		api.GET("/natives/search", SearchNatives)
 		api.GET("/native/:hash", GetNativeDetail)
 		api.GET("/native/:hash/source", GetNativeSource)
 		api.GET("/native/:hash/example", GetNativeExamples)
+		api.GET("/native/:hash/translations", GetNativeTranslations)
+		api.GET("/locales", GetLocales)
+		api.GET("/games", GetGames)
+		api.GET("/translations/outdated", GetOutdatedTranslations)
+		api.GET("/imports", GetImportRuns)
+		api.GET("/imports/:id", GetImportReport)
 		api.POST("/auth/login", LoginHandler)
 
 		// 管理接口
@@ -83,10 +94,57 @@ func registerRoutes(r *gin.Engine) {
 			protected.GET("/auth/me", GetCurrentUser)
 			protected.POST("/auth/change-password", ChangePasswordHandler)
 			protected.GET("/checkAuth", func(c *gin.Context) { c.Status(200) })
-			protected.POST("/native/:hash/translate", UpdateNativeTranslation)
-			protected.POST("/native/:hash/params", UpdateNativeParams)
-			protected.POST("/native/:hash/example", AddOrUpdateExample)
-			protected.DELETE("/native/:hash/example", DeleteExample)
+			protected.GET("/native/:hash/revisions", GetNativeRevisions)
+			protected.GET("/native/:hash/revisions/diff", DiffNativeRevisions)
+			protected.GET("/native/:hash/proposals", GetNativeProposals)
+
+			// 贡献者: 提交翻译 (需审核)，翻译者及以上直接发布
+			protected.POST("/native/:hash/translate", RequireRole(core.RoleContributor), UpdateNativeTranslation)
+			protected.POST("/native/:hash/params", RequireRole(core.RoleContributor), UpdateNativeParams)
+			protected.POST("/native/:hash/example", RequireRole(core.RoleTranslator), AddOrUpdateExample)
+
+			// 审核者: 删除示例、回滚修订、审核提案
+			protected.DELETE("/native/:hash/example", RequireRole(core.RoleReviewer), DeleteExample)
+			protected.POST("/native/:hash/revisions/:id/rollback", RequireRole(core.RoleReviewer), RollbackNativeRevision)
+
+			reviews := protected.Group("/reviews")
+			reviews.Use(RequireRole(core.RoleReviewer))
+			{
+				reviews.GET("", GetReviewQueue)
+				reviews.POST("/:id/approve", ApproveProposal)
+				reviews.POST("/:id/reject", RejectProposal)
+			}
+
+			// 管理员: 用户与术语表管理
+			admin := protected.Group("/admin")
+			admin.Use(RequireRole(core.RoleAdmin))
+			{
+				admin.GET("/users", AdminListUsers)
+				admin.POST("/users", AdminCreateUser)
+				admin.DELETE("/users/:username", AdminDeleteUser)
+				admin.POST("/users/:username/disable", AdminDisableUser)
+				admin.POST("/users/:username/enable", AdminEnableUser)
+				admin.POST("/users/:username/reset-password", AdminResetPassword)
+				admin.PUT("/users/:username/email", AdminUpdateEmail)
+				admin.PUT("/users/:username/role", AdminUpdateRole)
+
+				admin.GET("/glossary", AdminListGlossary)
+				admin.POST("/glossary", AdminCreateGlossary)
+				admin.GET("/glossary/check", AdminCheckGlossary)
+				admin.PUT("/glossary/:id", AdminUpdateGlossary)
+				admin.DELETE("/glossary/:id", AdminDeleteGlossary)
+
+				admin.GET("/translate/jobs", AdminListTranslateJobs)
+				admin.POST("/translate/jobs", AdminStartTranslateJob)
+				admin.GET("/translate/jobs/:id", AdminGetTranslateJob)
+				admin.GET("/translate/jobs/:id/events", AdminTranslateJobEvents)
+				admin.POST("/translate/jobs/:id/pause", AdminPauseTranslateJob)
+				admin.POST("/translate/jobs/:id/resume", AdminResumeTranslateJob)
+				admin.POST("/translate/jobs/:id/cancel", AdminCancelTranslateJob)
+
+				admin.GET("/sync", AdminGetSyncStatus)
+				admin.POST("/sync", AdminTriggerSync)
+			}
 		}
 	}
 }
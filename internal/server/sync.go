@@ -0,0 +1,118 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"nativedb/internal/commands"
+	"nativedb/internal/core"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	syncStateMu sync.Mutex
+	nextSyncAt  *time.Time
+)
+
+/**
+ * @brief 启动上游数据定时同步 (sync_interval 为 0 时不启动)
+ * @param config 应用配置
+ */
+func startSyncScheduler(config *core.AppConfig) {
+	if config.SyncInterval <= 0 {
+		return
+	}
+	if config.DataOffline {
+		log.Println("[Sync] Scheduled sync is disabled in offline mode.")
+		return
+	}
+	interval := time.Duration(config.SyncInterval) * time.Minute
+	log.Printf("[Sync] Checking upstream native data every %s.", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			next := time.Now().Add(interval)
+			syncStateMu.Lock()
+			nextSyncAt = &next
+			syncStateMu.Unlock()
+
+			<-ticker.C
+			if _, err := runSync("schedule"); err != nil {
+				log.Printf("[Sync] Scheduled sync skipped: %v", err)
+			}
+		}
+	}()
+}
+
+/**
+ * @brief 执行一次同步，导入了新数据时清除对应函数与列表的缓存
+ * @param triggeredBy 触发方式
+ * @return *core.SyncRun 同步记录
+ * @return error 同步进行中或离线模式
+ */
+func runSync(triggeredBy string) (*core.SyncRun, error) {
+	run, err := commands.SyncNatives(triggeredBy)
+	if err != nil {
+		return nil, err
+	}
+	for _, hash := range run.Written {
+		clearCache(hash)
+	}
+	log.Printf("[Sync] Sync #%d finished: %s (%d natives written).", run.ID, run.Status, len(run.Written))
+	return run, nil
+}
+
+/**
+ * @brief 在后台触发一次上游数据同步
+ * @param c Gin 上下文
+ */
+func AdminTriggerSync(c *gin.Context) {
+	if core.Config.DataOffline {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Sync is disabled in offline mode"})
+		return
+	}
+	if commands.SyncInProgress() {
+		c.JSON(http.StatusConflict, gin.H{"error": commands.ErrSyncRunning.Error()})
+		return
+	}
+	go func() {
+		if _, err := runSync("manual"); err != nil && !errors.Is(err, commands.ErrSyncRunning) {
+			log.Printf("[Sync] Manual sync failed: %v", err)
+		}
+	}()
+	c.JSON(http.StatusAccepted, gin.H{"status": core.SyncRunning})
+}
+
+/**
+ * @brief 获取同步状态与最近的同步记录
+ * @param c Gin 上下文
+ */
+func AdminGetSyncStatus(c *gin.Context) {
+	runs, err := core.ListSyncRuns(10)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	resp := gin.H{
+		"enabled":          core.Config.SyncInterval > 0 && !core.Config.DataOffline,
+		"interval_minutes": core.Config.SyncInterval,
+		"running":          commands.SyncInProgress(),
+		"last":             nil,
+		"history":          runs,
+	}
+	if len(runs) > 0 {
+		resp["last"] = runs[0]
+	}
+	syncStateMu.Lock()
+	if nextSyncAt != nil {
+		resp["next_run"] = *nextSyncAt
+	}
+	syncStateMu.Unlock()
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"nativedb/internal/commands"
+	"nativedb/internal/core"
+	"nativedb/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseKeepAlive 为 SSE 连接的心跳间隔，避免代理断开空闲连接
+const sseKeepAlive = 15 * time.Second
+
+/**
+ * @brief 根据路径参数查找翻译运行
+ * @param c Gin 上下文
+ * @return *commands.TranslationRun 翻译运行
+ * @return bool 是否找到，未找到时已写入响应
+ */
+func lookupTranslationRun(c *gin.Context) (*commands.TranslationRun, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job id"})
+		return nil, false
+	}
+	run := commands.GetTranslationRun(id)
+	if run == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Translation job not found"})
+		return nil, false
+	}
+	return run, true
+}
+
+/**
+ * @brief 在服务进程内启动 AI 翻译任务
+ * @param c Gin 上下文
+ */
+func AdminStartTranslateJob(c *gin.Context) {
+	var req models.TranslateJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts := commands.TranslateOptions{
+		Locale:           req.Locale,
+		Namespace:        req.Namespace,
+		Hashes:           req.Hashes,
+		Limit:            req.Limit,
+		BatchTokens:      core.Config.AiBatchTokens,
+		UntranslatedOnly: req.UntranslatedOnly,
+		RetryFailed:      req.RetryFailed,
+		IncludeReviewed:  req.IncludeReviewed,
+		NoMemory:         req.NoMemory,
+	}
+	if opts.Locale == "" {
+		opts.Locale = core.Config.AiTargetLocale
+	}
+	if req.BatchTokens != nil {
+		opts.BatchTokens = *req.BatchTokens
+	}
+
+	run, err := commands.StartTranslationRun(opts, func(e commands.ProgressEvent) {
+		// 译文写入后清除该函数的缓存
+		if e.Type == "progress" && (e.Status == "OK" || e.Status == "MEMORY" || e.Status == "BATCH") {
+			clearCache(e.Hash)
+		}
+	})
+	if err != nil {
+		if errors.Is(err, commands.ErrTranslationRunActive) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, run.Status())
+}
+
+/**
+ * @brief 获取最近的翻译任务
+ * @param c Gin 上下文
+ */
+func AdminListTranslateJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, commands.ListTranslationRuns())
+}
+
+/**
+ * @brief 获取翻译任务进度
+ * @param c Gin 上下文
+ */
+func AdminGetTranslateJob(c *gin.Context) {
+	run, ok := lookupTranslationRun(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, run.Status())
+}
+
+/**
+ * @brief 暂停翻译任务
+ * @param c Gin 上下文
+ */
+func AdminPauseTranslateJob(c *gin.Context) {
+	controlTranslateJob(c, (*commands.TranslationRun).Pause)
+}
+
+/**
+ * @brief 恢复翻译任务
+ * @param c Gin 上下文
+ */
+func AdminResumeTranslateJob(c *gin.Context) {
+	controlTranslateJob(c, (*commands.TranslationRun).Resume)
+}
+
+/**
+ * @brief 取消翻译任务，正在请求中的函数会立即中止，未完成的函数保持待翻译状态
+ * @param c Gin 上下文
+ */
+func AdminCancelTranslateJob(c *gin.Context) {
+	controlTranslateJob(c, (*commands.TranslationRun).Cancel)
+}
+
+/**
+ * @brief 对翻译任务执行暂停、恢复或取消操作
+ * @param c Gin 上下文
+ * @param action 操作
+ */
+func controlTranslateJob(c *gin.Context, action func(*commands.TranslationRun) error) {
+	run, ok := lookupTranslationRun(c)
+	if !ok {
+		return
+	}
+	if err := action(run); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, run.Status())
+}
+
+/**
+ * @brief 通过 SSE 推送翻译任务进度，任务结束时关闭连接
+ * @param c Gin 上下文
+ */
+func AdminTranslateJobEvents(c *gin.Context) {
+	run, ok := lookupTranslationRun(c)
+	if !ok {
+		return
+	}
+	events, unsubscribe := run.Subscribe()
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("state", commands.ProgressEvent{Type: "state", Run: run.Status()})
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(sseKeepAlive)
+	defer ticker.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(e.Type, e)
+			return true
+		case <-ticker.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
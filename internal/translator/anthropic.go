@@ -0,0 +1,122 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	AnthropicDefaultBaseURL = "https://api.anthropic.com"
+	AnthropicVersion        = "2023-06-01"
+)
+
+type MessagesRequest struct {
+	Model       string        `json:"model"`
+	System      string        `json:"system,omitempty"`
+	Messages    []ChatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+	MaxTokens   int           `json:"max_tokens"`
+}
+
+type MessagesResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage *struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage,omitempty"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// AnthropicProvider 兼容 Anthropic /v1/messages 接口
+type AnthropicProvider struct {
+	BaseURL string
+	ApiKey  string
+	Model   string
+	Client  *http.Client
+}
+
+/**
+ * @brief 获取后端名称
+ * @return string 名称
+ */
+func (p *AnthropicProvider) Name() string {
+	return ProviderAnthropic
+}
+
+/**
+ * @brief 调用 /v1/messages 进行翻译
+ * @param ctx 上下文
+ * @param r 翻译请求
+ * @return *Response 翻译结果
+ * @return error 调用错误
+ */
+func (p *AnthropicProvider) Translate(ctx context.Context, r Request) (*Response, error) {
+	reqBody := MessagesRequest{
+		Model:  p.Model,
+		System: r.SystemPrompt,
+		Messages: []ChatMessage{
+			{Role: "user", Content: r.Input},
+		},
+		Temperature: r.Temperature,
+		MaxTokens:   r.MaxTokens,
+	}
+	jsonData, _ := json.Marshal(reqBody)
+
+	// 兼容 base_url 已包含 /v1 的写法
+	url := strings.TrimRight(p.BaseURL, "/")
+	if strings.HasSuffix(url, "/v1") {
+		url += "/messages"
+	} else {
+		url += "/v1/messages"
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", p.ApiKey)
+	req.Header.Set("anthropic-version", AnthropicVersion)
+
+	body, err := doRequest(p.Client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var msgResp MessagesResponse
+	if err := json.Unmarshal(body, &msgResp); err != nil {
+		return nil, err
+	}
+	if msgResp.Error != nil {
+		return nil, fmt.Errorf("api error: %s", msgResp.Error.Message)
+	}
+
+	var text strings.Builder
+	for _, block := range msgResp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("no content")
+	}
+
+	result := &Response{Content: text.String()}
+	if msgResp.Usage != nil {
+		result.Usage = Usage{
+			PromptTokens:     msgResp.Usage.InputTokens,
+			CompletionTokens: msgResp.Usage.OutputTokens,
+			TotalTokens:      msgResp.Usage.InputTokens + msgResp.Usage.OutputTokens,
+		}
+	}
+	return result, nil
+}
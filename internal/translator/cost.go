@@ -0,0 +1,120 @@
+package translator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CostReport 汇总一次翻译运行的请求数、token 用量、等待时间与费用
+type CostReport struct {
+	mu               sync.Mutex
+	start            time.Time
+	Requests         int
+	Failures         int
+	Retries          int
+	PromptTokens     int
+	CompletionTokens int
+	Waited           time.Duration
+	PricePrompt      float64
+	PriceCompletion  float64
+}
+
+/**
+ * @brief 创建费用统计
+ * @param pricePrompt 输入 token 单价 (每百万 token)
+ * @param priceCompletion 输出 token 单价 (每百万 token)
+ * @return *CostReport 费用统计
+ */
+func NewCostReport(pricePrompt, priceCompletion float64) *CostReport {
+	return &CostReport{start: time.Now(), PricePrompt: pricePrompt, PriceCompletion: priceCompletion}
+}
+
+/**
+ * @brief 记录一次请求
+ * @param usage token 用量
+ * @param err 请求错误
+ */
+func (r *CostReport) AddRequest(usage Usage, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Requests++
+	if err != nil {
+		r.Failures++
+	}
+	r.PromptTokens += usage.PromptTokens
+	r.CompletionTokens += usage.CompletionTokens
+}
+
+/**
+ * @brief 记录一次重试
+ */
+func (r *CostReport) AddRetry() {
+	r.mu.Lock()
+	r.Retries++
+	r.mu.Unlock()
+}
+
+/**
+ * @brief 记录限流等待时间
+ * @param d 等待时间
+ */
+func (r *CostReport) AddWait(d time.Duration) {
+	r.mu.Lock()
+	r.Waited += d
+	r.mu.Unlock()
+}
+
+/**
+ * @brief 获取累计 token 数
+ * @return int token 数
+ */
+func (r *CostReport) TotalTokens() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.PromptTokens + r.CompletionTokens
+}
+
+/**
+ * @brief 获取累计请求数
+ * @return int 请求数
+ */
+func (r *CostReport) RequestCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.Requests
+}
+
+/**
+ * @brief 按单价计算累计费用
+ * @return float64 费用
+ */
+func (r *CostReport) Cost() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cost()
+}
+
+/**
+ * @brief 计算累计费用 (调用方需持有锁)
+ * @return float64 费用
+ */
+func (r *CostReport) cost() float64 {
+	return (float64(r.PromptTokens)*r.PricePrompt + float64(r.CompletionTokens)*r.PriceCompletion) / 1e6
+}
+
+/**
+ * @brief 生成费用报告
+ * @return string 多行报告
+ */
+func (r *CostReport) Summary() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	elapsed := time.Since(r.start).Round(time.Second)
+	s := fmt.Sprintf("Requests: %d (failed: %d, retries: %d), Rate limit wait: %s, Elapsed: %s\n", r.Requests, r.Failures, r.Retries, r.Waited.Round(time.Second), elapsed)
+	s += fmt.Sprintf("Tokens: %d prompt + %d completion = %d", r.PromptTokens, r.CompletionTokens, r.PromptTokens+r.CompletionTokens)
+	if r.PricePrompt > 0 || r.PriceCompletion > 0 {
+		s += fmt.Sprintf(", Estimated cost: %.4f", r.cost())
+	}
+	return s
+}
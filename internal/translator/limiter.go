@@ -0,0 +1,170 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	backoffBase = time.Second
+	backoffMax  = time.Minute
+)
+
+// bucket 为按速率持续补充的令牌桶，rate 为每秒补充的令牌数
+type bucket struct {
+	capacity  float64
+	available float64
+	rate      float64
+	last      time.Time
+}
+
+// RateLimiter 同时按每分钟请求数与每分钟 token 数限制 AI 调用，限制为 0 时不生效
+type RateLimiter struct {
+	mu       sync.Mutex
+	requests *bucket
+	tokens   *bucket
+}
+
+/**
+ * @brief 创建令牌桶
+ * @param perMinute 每分钟允许的数量，不大于 0 时返回 nil (不限制)
+ * @return *bucket 令牌桶
+ */
+func newBucket(perMinute int) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &bucket{
+		capacity:  float64(perMinute),
+		available: float64(perMinute),
+		rate:      float64(perMinute) / 60,
+		last:      time.Now(),
+	}
+}
+
+/**
+ * @brief 按经过的时间补充令牌
+ * @param now 当前时间
+ */
+func (b *bucket) refill(now time.Time) {
+	b.available = min(b.capacity, b.available+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+/**
+ * @brief 计算取得指定数量令牌还需等待的时间
+ * @param n 令牌数量
+ * @return time.Duration 等待时间
+ */
+func (b *bucket) delay(n float64) time.Duration {
+	if b == nil || b.available >= n {
+		return 0
+	}
+	return time.Duration((n - b.available) / b.rate * float64(time.Second))
+}
+
+/**
+ * @brief 创建限流器
+ * @param rpm 每分钟请求数上限，0 表示不限制
+ * @param tpm 每分钟 token 数上限，0 表示不限制
+ * @return *RateLimiter 限流器，两项均不限制时返回 nil
+ */
+func NewRateLimiter(rpm, tpm int) *RateLimiter {
+	if rpm <= 0 && tpm <= 0 {
+		return nil
+	}
+	return &RateLimiter{requests: newBucket(rpm), tokens: newBucket(tpm)}
+}
+
+/**
+ * @brief 等待直到可以发送一个预计消耗 tokens 的请求
+ * @param ctx 上下文
+ * @param tokens 预计消耗的 token 数
+ * @return time.Duration 实际等待的时间
+ * @return error 上下文取消时返回错误
+ */
+func (l *RateLimiter) Wait(ctx context.Context, tokens int) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+	start := time.Now()
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		need := float64(tokens)
+		if l.requests != nil {
+			l.requests.refill(now)
+		}
+		if l.tokens != nil {
+			l.tokens.refill(now)
+			// 单个请求超过桶容量时按桶容量计算，避免永远等待
+			need = min(need, l.tokens.capacity)
+		}
+		wait := max(l.requests.delay(1), l.tokens.delay(need))
+		if wait == 0 {
+			if l.requests != nil {
+				l.requests.available--
+			}
+			if l.tokens != nil {
+				l.tokens.available -= need
+			}
+			l.mu.Unlock()
+			return time.Since(start), nil
+		}
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return time.Since(start), ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+/**
+ * @brief 按实际用量修正 token 桶 (实际用量超出预估时扣除差额，反之归还)
+ * @param delta 实际用量与预估用量的差值
+ */
+func (l *RateLimiter) Adjust(delta int) {
+	if l == nil || l.tokens == nil || delta == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens.refill(time.Now())
+	l.tokens.available = min(l.tokens.capacity, l.tokens.available-float64(delta))
+}
+
+/**
+ * @brief 判断错误是否值得重试 (限流、服务端错误与网络错误)
+ * @param err 调用错误
+ * @return bool 是否可重试
+ */
+func Retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == 429 || apiErr.StatusCode >= 500
+	}
+	return !errors.Is(err, context.Canceled)
+}
+
+/**
+ * @brief 计算第 attempt 次重试前的等待时间，优先使用 Retry-After
+ * @param attempt 已失败的次数 (从 0 开始)
+ * @param err 调用错误
+ * @return time.Duration 等待时间
+ */
+func Backoff(attempt int, err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return min(apiErr.RetryAfter, backoffMax)
+	}
+	d := min(backoffBase<<attempt, backoffMax)
+	// 加入最多 50% 的随机抖动，避免多个 worker 同时重试
+	return d/2 + rand.N(d/2+1)
+}
@@ -0,0 +1,80 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// MockProvider 为本地确定性翻译后端，不访问网络，用于离线调试与 CI
+// 输出为在原文前加上 "[语言代码] " 前缀
+type MockProvider struct{}
+
+/**
+ * @brief 获取后端名称
+ * @return string 名称
+ */
+func (p *MockProvider) Name() string {
+	return ProviderMock
+}
+
+/**
+ * @brief 生成确定性的翻译结果
+ * @param ctx 上下文
+ * @param r 翻译请求
+ * @return *Response 翻译结果
+ * @return error 输入不是合法 JSON 时返回错误
+ */
+func (p *MockProvider) Translate(ctx context.Context, r Request) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	prefix := "[" + r.Locale + "] "
+	var content []byte
+	var batch BatchInput
+	if err := json.Unmarshal([]byte(r.Input), &batch); err == nil && len(batch.Natives) > 0 {
+		output := BatchOutput{Natives: make(map[string]Output, len(batch.Natives))}
+		for hash, input := range batch.Natives {
+			output.Natives[hash] = mockTranslate(prefix, input)
+		}
+		content, _ = json.Marshal(output)
+	} else {
+		var input Input
+		if err := json.Unmarshal([]byte(r.Input), &input); err != nil {
+			return nil, fmt.Errorf("mock provider: invalid input: %v", err)
+		}
+		content, _ = json.Marshal(mockTranslate(prefix, input))
+	}
+
+	promptTokens := EstimateTokens(r.SystemPrompt) + EstimateTokens(r.Input)
+	completionTokens := EstimateTokens(string(content))
+	return &Response{
+		Content: string(content),
+		Usage: Usage{
+			PromptTokens:     promptTokens,
+			CompletionTokens: completionTokens,
+			TotalTokens:      promptTokens + completionTokens,
+		},
+	}, nil
+}
+
+/**
+ * @brief 为单个函数生成带前缀的译文
+ * @param prefix 译文前缀
+ * @param input 函数输入
+ * @return Output 译文
+ */
+func mockTranslate(prefix string, input Input) Output {
+	output := Output{
+		Segments: make(map[string]string, len(input.Segments)),
+		Params:   make(map[string]string, len(input.Params)),
+	}
+	for key, text := range input.Segments {
+		output.Segments[key] = prefix + text
+	}
+	for name, text := range input.Params {
+		output.Params[name] = prefix + text
+	}
+	return output
+}
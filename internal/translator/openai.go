@@ -0,0 +1,108 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ChatCompletionRequest struct {
+	Model          string        `json:"model"`
+	Messages       []ChatMessage `json:"messages"`
+	Temperature    float64       `json:"temperature"`
+	ResponseFormat *FormatObj    `json:"response_format,omitempty"`
+	MaxTokens      int           `json:"max_tokens"`
+	Stream         bool          `json:"stream"`
+}
+
+type FormatObj struct {
+	Type string `json:"type"`
+}
+
+type ChatCompletionResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Usage *Usage `json:"usage,omitempty"`
+	Error *struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error,omitempty"`
+}
+
+// OpenAIProvider 兼容 OpenAI /chat/completions 接口 (DeepSeek 等)
+type OpenAIProvider struct {
+	BaseURL string
+	ApiKey  string
+	Model   string
+	Client  *http.Client
+}
+
+/**
+ * @brief 获取后端名称
+ * @return string 名称
+ */
+func (p *OpenAIProvider) Name() string {
+	return ProviderOpenAI
+}
+
+/**
+ * @brief 调用 /chat/completions 进行翻译
+ * @param ctx 上下文
+ * @param r 翻译请求
+ * @return *Response 翻译结果
+ * @return error 调用错误
+ */
+func (p *OpenAIProvider) Translate(ctx context.Context, r Request) (*Response, error) {
+	reqBody := ChatCompletionRequest{
+		Model: p.Model,
+		Messages: []ChatMessage{
+			{Role: "system", Content: r.SystemPrompt},
+			{Role: "user", Content: r.Input},
+		},
+		Temperature:    r.Temperature,
+		ResponseFormat: &FormatObj{Type: "json_object"},
+		MaxTokens:      r.MaxTokens,
+	}
+	jsonData, _ := json.Marshal(reqBody)
+
+	url := strings.TrimRight(p.BaseURL, "/") + "/chat/completions"
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.ApiKey)
+
+	body, err := doRequest(p.Client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var chatResp ChatCompletionResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return nil, err
+	}
+	if chatResp.Error != nil {
+		return nil, fmt.Errorf("api error: %s", chatResp.Error.Message)
+	}
+	if len(chatResp.Choices) == 0 {
+		return nil, fmt.Errorf("no choices")
+	}
+
+	result := &Response{Content: chatResp.Choices[0].Message.Content}
+	if chatResp.Usage != nil {
+		result.Usage = *chatResp.Usage
+	}
+	return result, nil
+}
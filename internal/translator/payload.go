@@ -0,0 +1,34 @@
+package translator
+
+// Input 为发送给模型的 JSON 输入，segments 为描述中需要翻译的段落 (键为段落序号)
+type Input struct {
+	NativeName string               `json:"native_name"`
+	Segments   map[string]string    `json:"segments"`
+	Params     map[string]string    `json:"params"`
+	References map[string]Reference `json:"references,omitempty"`
+}
+
+// Reference 为翻译记忆中与某段落相似的已审核译文，供模型参考措辞
+type Reference struct {
+	Source      string `json:"source"`
+	Translation string `json:"translation"`
+}
+
+// Output 为模型返回的 JSON，description / description_cn / params_cn 为旧版提示词的返回字段，仍然兼容
+type Output struct {
+	Segments      map[string]string `json:"segments"`
+	Params        map[string]string `json:"params"`
+	Description   string            `json:"description,omitempty"`
+	DescriptionCn string            `json:"description_cn,omitempty"`
+	ParamsCn      map[string]string `json:"params_cn,omitempty"`
+}
+
+// BatchInput 为批量模式下发送给模型的 JSON 输入，键为函数哈希
+type BatchInput struct {
+	Natives map[string]Input `json:"natives"`
+}
+
+// BatchOutput 为批量模式下模型返回的 JSON，键为函数哈希
+type BatchOutput struct {
+	Natives map[string]Output `json:"natives"`
+}
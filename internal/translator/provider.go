@@ -0,0 +1,159 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"nativedb/internal/core"
+)
+
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderMock      = "mock"
+)
+
+type Request struct {
+	Locale       string
+	SystemPrompt string
+	Input        string
+	Temperature  float64
+	MaxTokens    int
+}
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type Response struct {
+	Content string
+	Usage   Usage
+}
+
+// APIError 为翻译接口返回的非 200 响应，保留响应体与 Retry-After
+type APIError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+/**
+ * @brief 获取错误描述
+ * @return string 错误描述
+ */
+func (e *APIError) Error() string {
+	body := strings.TrimSpace(e.Body)
+	if len(body) > 500 {
+		body = body[:500] + "..."
+	}
+	if body == "" {
+		return fmt.Sprintf("api status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("api status %d: %s", e.StatusCode, body)
+}
+
+// TranslationProvider 为 AI 翻译后端，输入与输出均为 JSON 文本
+type TranslationProvider interface {
+	Name() string
+	Translate(ctx context.Context, req Request) (*Response, error)
+}
+
+/**
+ * @brief 根据配置创建翻译后端
+ * @param config 应用配置
+ * @return TranslationProvider 翻译后端
+ * @return error 配置错误
+ */
+func NewProvider(config *core.AppConfig) (TranslationProvider, error) {
+	name := strings.ToLower(config.AiProvider)
+	if name == "" {
+		name = ProviderOpenAI
+	}
+	if name == ProviderMock {
+		return &MockProvider{}, nil
+	}
+
+	if config.AiApiKey == "" || config.AiApiKey == "your-api-key-here" {
+		return nil, fmt.Errorf("AI API Key not configured. Please edit config.json")
+	}
+	client := &http.Client{Timeout: 60 * time.Second}
+
+	switch name {
+	case ProviderOpenAI:
+		return &OpenAIProvider{BaseURL: config.AiBaseUrl, ApiKey: config.AiApiKey, Model: config.AiModel, Client: client}, nil
+	case ProviderAnthropic:
+		baseURL := config.AiBaseUrl
+		if baseURL == "" {
+			baseURL = AnthropicDefaultBaseURL
+		}
+		return &AnthropicProvider{BaseURL: baseURL, ApiKey: config.AiApiKey, Model: config.AiModel, Client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown AI provider: %s (supported: %s, %s, %s)", config.AiProvider, ProviderOpenAI, ProviderAnthropic, ProviderMock)
+	}
+}
+
+/**
+ * @brief 按约 4 字符 1 token 粗略估算文本的 token 数
+ * @param text 文本
+ * @return int token 数
+ */
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+/**
+ * @brief 发送请求并读取响应体，非 200 状态码视为错误
+ * @param client HTTP 客户端
+ * @param req HTTP 请求
+ * @return []byte 响应体
+ * @return error 请求错误
+ */
+func doRequest(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	return body, nil
+}
+
+/**
+ * @brief 解析 Retry-After 头 (秒数或 HTTP 日期)
+ * @param value 头部值
+ * @return time.Duration 等待时间，无法解析时为 0
+ */
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}